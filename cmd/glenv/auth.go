@@ -0,0 +1,234 @@
+//nolint:errcheck // CLI output errors are intentionally ignored
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ohmylock/glenv/pkg/config"
+	"github.com/ohmylock/glenv/pkg/gitlab"
+)
+
+// tokenExpiryWarning is how close to expiry a token is reported in yellow.
+const tokenExpiryWarning = 14 * 24 * time.Hour
+
+// tokenSourceOAuthLogin is the token source of tokens saved by auth login.
+const tokenSourceOAuthLogin = "glenv auth login"
+
+// AuthCommand groups the auth subcommands.
+type AuthCommand struct{}
+
+// AuthStatusCommand shows which source provided the token and what the token
+// is allowed to do.
+type AuthStatusCommand struct {
+	global *GlobalOptions
+}
+
+func (cmd *AuthStatusCommand) Execute(args []string) error {
+	setupColor(cmd.global.NoColor)
+	cfg, err := loadGlobalConfig(cmd.global)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("GitLab:  %s\n", cfg.GitLab.URL)
+	if cfg.GitLab.Token == "" {
+		red.Println("Token:   not found")
+		fmt.Println("\nSet GITLAB_TOKEN or --token, add token or token_command to .glenv.yml,")
+		fmt.Println("or store the token in a git credential helper, ~/.netrc or glab.")
+		return errors.New("no GitLab token found")
+	}
+	fmt.Printf("Token:   %s (from %s)\n", maskToken(cfg.GitLab.Token), cfg.GitLab.TokenSource)
+
+	client, err := newClient(cfg, cmd.global)
+	if err != nil {
+		return err
+	}
+	if cfg.GitLab.Auth == config.AuthJobToken {
+		gray.Println("Status:  not verifiable (job tokens cannot query the current user)")
+		return nil
+	}
+	user, err := client.CurrentUser(appCtx)
+	if err != nil {
+		red.Println("Status:  invalid")
+		return fmt.Errorf("authenticate: %w", err)
+	}
+	green.Println("Status:  valid")
+	fmt.Printf("User:    %s\n", user.Username)
+
+	info, err := client.GetTokenInfo(appCtx)
+	if err != nil {
+		// OAuth and job tokens cannot be inspected.
+		gray.Printf("Scopes:  unavailable (%v)\n", err)
+		return nil
+	}
+	fmt.Printf("Name:    %s\n", info.Name)
+	fmt.Printf("Scopes:  %s\n", strings.Join(info.Scopes, ", "))
+	printTokenExpiry(info, time.Now())
+	return nil
+}
+
+// AuthLoginCommand obtains an OAuth token through the device flow and saves
+// it for use with gitlab.auth: oauth.
+type AuthLoginCommand struct {
+	ClientID string   `long:"client-id" description:"OAuth application ID (default: gitlab.oauth_client_id)"`
+	Scopes   []string `long:"scope" description:"OAuth scope to request (repeatable)" default:"api"`
+	global   *GlobalOptions
+}
+
+func (cmd *AuthLoginCommand) Execute(args []string) error {
+	setupColor(cmd.global.NoColor)
+	cfg, err := loadGlobalConfig(cmd.global)
+	if err != nil {
+		return err
+	}
+	clientID := cmd.ClientID
+	if clientID == "" {
+		clientID = cfg.GitLab.OAuthClientID
+	}
+	if clientID == "" {
+		return errors.New("an OAuth application ID is required: pass --client-id or set gitlab.oauth_client_id")
+	}
+	httpClient, err := newHTTPClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	app := gitlab.OAuthApp{BaseURL: cfg.GitLab.URL, ClientID: clientID, HTTP: httpClient}
+	code, err := app.StartDeviceFlow(appCtx, cmd.Scopes)
+	if err != nil {
+		return err
+	}
+	verifyURL := code.VerificationURI
+	if code.VerificationURIComplete != "" {
+		verifyURL = code.VerificationURIComplete
+	}
+	fmt.Printf("Open %s and enter the code:\n\n    %s\n\nWaiting for confirmation...\n", verifyURL, code.UserCode)
+
+	token, err := app.PollDeviceFlow(appCtx, code)
+	if err != nil {
+		return err
+	}
+	path, err := saveOAuthToken(cfg.GitLab.URL, token)
+	if err != nil {
+		return err
+	}
+	green.Printf("✓ Logged in; token saved to %s\n", path)
+	if cfg.GitLab.Auth != config.AuthOAuth {
+		fmt.Println("Set gitlab.auth: oauth in .glenv.yml to use it.")
+	}
+	return nil
+}
+
+// newAuthenticator returns how the client authenticates for cfg.GitLab.Auth.
+// OAuth tokens saved by auth login are refreshed and re-saved as needed.
+func newAuthenticator(cfg *config.Config, httpClient *http.Client) (gitlab.Authenticator, error) {
+	switch cfg.GitLab.Auth {
+	case config.AuthJobToken:
+		return gitlab.JobToken(cfg.GitLab.Token), nil
+	case config.AuthOAuth:
+		if cfg.GitLab.TokenSource != tokenSourceOAuthLogin {
+			return gitlab.BearerToken(cfg.GitLab.Token), nil
+		}
+		token, err := loadOAuthToken(cfg.GitLab.URL)
+		if err != nil {
+			return nil, err
+		}
+		app := gitlab.OAuthApp{BaseURL: cfg.GitLab.URL, ClientID: cfg.GitLab.OAuthClientID, HTTP: httpClient}
+		return gitlab.NewOAuth(app, token, func(t *gitlab.OAuthToken) {
+			if _, err := saveOAuthToken(cfg.GitLab.URL, t); err != nil {
+				yellow.Fprintf(os.Stderr, "warning: save refreshed OAuth token: %v\n", err)
+			}
+		}), nil
+	default:
+		return gitlab.PrivateToken(cfg.GitLab.Token), nil
+	}
+}
+
+// oauthTokenPath returns where auth login saves the token for baseURL.
+func oauthTokenPath(baseURL string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config directory: %w", err)
+	}
+	host := baseURL
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return filepath.Join(dir, "glenv", "oauth", strings.ReplaceAll(host, ":", "_")+".json"), nil
+}
+
+// loadOAuthToken returns the token saved for baseURL, or nil if there is none.
+func loadOAuthToken(baseURL string) (*gitlab.OAuthToken, error) {
+	path, err := oauthTokenPath(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is derived from the config directory
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read OAuth token: %w", err)
+	}
+	var token gitlab.OAuthToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("parse OAuth token %s: %w", path, err)
+	}
+	return &token, nil
+}
+
+// saveOAuthToken writes token for baseURL readable only by the user.
+func saveOAuthToken(baseURL string, token *gitlab.OAuthToken) (string, error) {
+	path, err := oauthTokenPath(baseURL)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", fmt.Errorf("save OAuth token: %w", err)
+	}
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", fmt.Errorf("save OAuth token: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("save OAuth token: %w", err)
+	}
+	return path, nil
+}
+
+// printTokenExpiry prints the expiry date, highlighting tokens that expire soon.
+func printTokenExpiry(info *gitlab.TokenInfo, now time.Time) {
+	if info.ExpiresAt == nil {
+		fmt.Println("Expires: never")
+		return
+	}
+	left := info.ExpiresAt.Sub(now)
+	switch {
+	case left < 0:
+		red.Printf("Expires: %s (expired)\n", info.ExpiresAt)
+	case left < tokenExpiryWarning:
+		yellow.Printf("Expires: %s (in %d days)\n", info.ExpiresAt, int(left.Hours()/24))
+	default:
+		fmt.Printf("Expires: %s\n", info.ExpiresAt)
+	}
+}
+
+// maskToken shows only the token's prefix and last four characters.
+func maskToken(token string) string {
+	if len(token) <= 8 {
+		return strings.Repeat("*", len(token))
+	}
+	prefix := ""
+	if i := strings.Index(token, "-"); i > 0 && i < 8 {
+		prefix = token[:i+1]
+	}
+	return prefix + "****" + token[len(token)-4:]
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ohmylock/glenv/pkg/config"
+	"github.com/ohmylock/glenv/pkg/gitlab"
+)
+
+func TestMaskToken(t *testing.T) {
+	tests := []struct {
+		token, want string
+	}{
+		{"glpat-abcdefghijklmnop", "glpat-****mnop"},
+		{"abcdefghijklmnop", "****mnop"},
+		{"short", "*****"},
+	}
+	for _, tt := range tests {
+		if got := maskToken(tt.token); got != tt.want {
+			t.Errorf("maskToken(%q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+}
+
+func TestOAuthTokenStore(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	if tok, err := loadOAuthToken("https://gitlab.example.com:8443"); err != nil || tok != nil {
+		t.Fatalf("loadOAuthToken(missing) = %v, %v; want nil, nil", tok, err)
+	}
+	path, err := saveOAuthToken("https://gitlab.example.com:8443", &gitlab.OAuthToken{AccessToken: "a", RefreshToken: "r"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("saved token mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+	tok, err := loadOAuthToken("https://gitlab.example.com:8443")
+	if err != nil || tok == nil || tok.RefreshToken != "r" {
+		t.Errorf("loadOAuthToken() = %+v, %v", tok, err)
+	}
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	tests := []struct {
+		auth, source, want string
+	}{
+		{"", config.TokenSourceEnv, "PRIVATE-TOKEN"},
+		{config.AuthJobToken, config.TokenSourceJobToken, "CI job token"},
+		{config.AuthOAuth, config.TokenSourceEnv, "OAuth access token"},
+	}
+	for _, tt := range tests {
+		cfg := &config.Config{GitLab: config.GitLabConfig{Token: "t", Auth: tt.auth, TokenSource: tt.source}}
+		auth, err := newAuthenticator(cfg, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := auth.String(); got != tt.want {
+			t.Errorf("newAuthenticator(auth=%q) = %s, want %s", tt.auth, got, tt.want)
+		}
+	}
+}
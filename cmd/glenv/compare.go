@@ -0,0 +1,93 @@
+//nolint:errcheck // CLI output errors are intentionally ignored
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ohmylock/glenv/pkg/gitlab"
+	glsync "github.com/ohmylock/glenv/pkg/sync"
+)
+
+// CompareCommand compares the variables of two environments or projects.
+type CompareCommand struct {
+	ProjectA string `long:"project-a" description:"Project of the first environment (default: configured project)"`
+	ProjectB string `long:"project-b" description:"Project of the second environment (default: same as --project-a)"`
+	All      bool   `long:"all" description:"Also list keys that are identical on both sides"`
+	global   *GlobalOptions
+}
+
+func (cmd *CompareCommand) Execute(args []string) error {
+	setupColor(cmd.global.NoColor)
+	if len(args) != 2 {
+		return fmt.Errorf("usage: glenv compare ENV_A ENV_B [--project-a ID] [--project-b ID]")
+	}
+	scopeA, scopeB := args[0], args[1]
+
+	cfg, client, err := buildClientFromGlobal(cmd.global)
+	if err != nil {
+		return err
+	}
+	projectA := cmd.ProjectA
+	if projectA == "" {
+		projectA = cfg.GitLab.ProjectID
+	}
+	projectB := cmd.ProjectB
+	if projectB == "" {
+		projectB = projectA
+	}
+
+	varsA, err := client.ListVariables(appCtx, projectA, gitlab.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list variables of project %s: %w", projectA, err)
+	}
+	varsB := varsA
+	if projectB != projectA {
+		varsB, err = client.ListVariables(appCtx, projectB, gitlab.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("list variables of project %s: %w", projectB, err)
+		}
+	}
+
+	nameA, nameB := scopeA, scopeB
+	if projectA != projectB {
+		nameA, nameB = projectA+":"+scopeA, projectB+":"+scopeB
+	}
+	fmt.Printf("Comparing %s ↔ %s\n\n", nameA, nameB)
+
+	var onlyA, onlyB, differ, same int
+	for _, c := range glsync.Compare(varsA, scopeA, varsB, scopeB) {
+		switch c.Kind {
+		case glsync.CompareOnlyA:
+			onlyA++
+			red.Printf("- %s (only in %s)\n", c.Key, nameA)
+		case glsync.CompareOnlyB:
+			onlyB++
+			green.Printf("+ %s (only in %s)\n", c.Key, nameB)
+		case glsync.CompareDiffers:
+			differ++
+			yellow.Printf("~ %s: %s [%s]\n", c.Key, compareValues(*c.A, *c.B, c.Differences), strings.Join(c.Differences, ", "))
+		case glsync.CompareSame:
+			same++
+			if cmd.All {
+				cyan.Printf("= %s\n", c.Key)
+			}
+		}
+	}
+	fmt.Printf("\nOnly in %s: %d | Only in %s: %d | Different: %d | Same: %d\n", nameA, onlyA, nameB, onlyB, differ, same)
+	return nil
+}
+
+// compareValues renders the values of a differing key for the compare output.
+// If either side is secret, only whether the values differ is shown: even a
+// short hash of a short secret can be brute-forced.
+func compareValues(a, b gitlab.Variable, differences []string) string {
+	if glsync.IsSecret(a) || glsync.IsSecret(b) {
+		if slices.Contains(differences, "value") {
+			return "(differs)"
+		}
+		return "(same)"
+	}
+	return displayValue(a, false) + " → " + displayValue(b, false)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ohmylock/glenv/pkg/gitlab"
+)
+
+func TestCompareValues(t *testing.T) {
+	secret := gitlab.Variable{Value: "abcdefgh12345678", Masked: true}
+	plain := gitlab.Variable{Value: "debug"}
+
+	got := compareValues(secret, plain, []string{"value", "masked"})
+	if got != "(differs)" {
+		t.Errorf("compareValues(secret, plain) = %q, want (differs)", got)
+	}
+	if strings.Contains(got, "abcdefgh") || strings.Contains(got, "debug") {
+		t.Errorf("compareValues(secret, plain) = %q leaks a value", got)
+	}
+	if got := compareValues(secret, secret, []string{"protected"}); got != "(same)" {
+		t.Errorf("compareValues(secret, secret) = %q, want (same)", got)
+	}
+	if got := compareValues(plain, gitlab.Variable{Value: "info"}, []string{"value"}); got != "debug → info" {
+		t.Errorf("compareValues(plain, plain) = %q, want debug → info", got)
+	}
+}
@@ -0,0 +1,49 @@
+//nolint:errcheck // CLI output errors are intentionally ignored
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ohmylock/glenv/pkg/config"
+)
+
+// ConfigCommand groups the config subcommands.
+type ConfigCommand struct{}
+
+// ConfigShowCommand prints the resolved configuration and where each value
+// came from.
+type ConfigShowCommand struct {
+	global *GlobalOptions
+}
+
+func (cmd *ConfigShowCommand) Execute(args []string) error {
+	setupColor(cmd.global.NoColor)
+	cfg, err := loadGlobalConfig(cmd.global)
+	if err != nil {
+		return err
+	}
+	return printSettings(os.Stdout, cfg.Settings())
+}
+
+// printSettings writes settings as a KEY/VALUE/ORIGIN table, redacting secrets.
+func printSettings(out io.Writer, settings []config.Setting) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tORIGIN")
+	for _, s := range settings {
+		value := s.Value
+		if s.Secret && value != "" {
+			value = maskToken(value)
+		}
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, value, s.Origin)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+	return nil
+}
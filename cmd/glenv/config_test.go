@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ohmylock/glenv/pkg/config"
+)
+
+func TestPrintSettings(t *testing.T) {
+	var buf bytes.Buffer
+	err := printSettings(&buf, []config.Setting{
+		{Key: "gitlab.url", Value: "https://gitlab.internal", Origin: "profile internal"},
+		{Key: "gitlab.token", Value: "glpat-abcdefghijklmnop", Origin: "netrc", Secret: true},
+		{Key: "pipeline.ref", Origin: config.OriginDefault},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "abcdefghijkl") {
+		t.Errorf("token is not redacted:\n%s", out)
+	}
+	for _, want := range []string{"glpat-****mnop", "profile internal", "pipeline.ref  -"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestLoadGlobalConfig_FlagOrigins(t *testing.T) {
+	appCtx = context.Background()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GLENV_PROFILE", "")
+	t.Setenv("GITLAB_TOKEN", "glpat-same-value")
+	t.Setenv("GITLAB_PROJECT_ID", "42")
+	t.Setenv("GITLAB_URL", "")
+	path := filepath.Join(t.TempDir(), ".glenv.yml")
+	if err := os.WriteFile(path, []byte("gitlab:\n  url: https://gitlab.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadGlobalConfig(&GlobalOptions{Config: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GitLab.TokenSource != config.TokenSourceEnv {
+		t.Errorf("token source = %q, want %q", cfg.GitLab.TokenSource, config.TokenSourceEnv)
+	}
+
+	// A flag equal to the environment value still counts as the flag.
+	cfg, err = loadGlobalConfig(&GlobalOptions{Config: path, Token: "glpat-same-value", Project: "42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GitLab.TokenSource != config.TokenSourceFlag {
+		t.Errorf("token source = %q, want %q", cfg.GitLab.TokenSource, config.TokenSourceFlag)
+	}
+	for _, s := range cfg.Settings() {
+		if s.Key == "gitlab.project_id" && s.Origin != "--project flag" {
+			t.Errorf("project_id origin = %q, want --project flag", s.Origin)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+//nolint:errcheck // CLI output errors are intentionally ignored
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/ohmylock/glenv/pkg/gitlab"
+)
+
+// EffectiveCommand resolves variables across instance, group and project layers.
+type EffectiveCommand struct {
+	Environment string `short:"e" long:"environment" description:"Environment name (e.g. production)" required:"true"`
+	Reveal      bool   `long:"reveal" description:"Show values of masked variables"`
+	global      *GlobalOptions
+}
+
+func (cmd *EffectiveCommand) Execute(args []string) error {
+	setupColor(cmd.global.NoColor)
+	cfg, client, err := buildClientFromGlobal(cmd.global)
+	if err != nil {
+		return err
+	}
+
+	layers, err := fetchLayers(client, cfg.GitLab.ProjectID)
+	if err != nil {
+		return err
+	}
+	resolved := gitlab.ResolveLayers(layers, cmd.Environment)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE\tSCOPE")
+	for _, ev := range resolved {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", ev.Key, displayValue(ev.Variable, cmd.Reveal), ev.Layer, ev.EnvironmentScope)
+		for _, sh := range ev.Shadowed {
+			fmt.Fprintf(w, "  ↳ overrides\t%s\t%s\t%s\n", displayValue(sh.Variable, cmd.Reveal), sh.Layer, sh.Variable.EnvironmentScope)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+	fmt.Printf("\nTotal: %d variables visible in %s\n", len(resolved), cmd.Environment)
+	return nil
+}
+
+// fetchLayers collects variable layers ordered from lowest to highest
+// precedence: instance, parent groups from the top down, then the project.
+// Instance variables need administrator access and are skipped with a
+// warning when the token is not allowed to read them.
+func fetchLayers(client *gitlab.Client, projectID string) ([]gitlab.Layer, error) {
+	var layers []gitlab.Layer
+
+	instanceVars, err := client.ListInstanceVariables(appCtx)
+	switch {
+	case errors.Is(err, gitlab.ErrForbidden):
+		yellow.Fprintln(os.Stderr, "warning: instance variables require administrator access; skipped")
+	case err != nil:
+		return nil, fmt.Errorf("list instance variables: %w", err)
+	default:
+		layers = append(layers, gitlab.Layer{Name: "instance", Variables: instanceVars})
+	}
+
+	groups, err := client.GroupChain(appCtx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve parent groups: %w", err)
+	}
+	for _, g := range groups {
+		vars, err := client.ListGroupVariables(appCtx, strconv.Itoa(g.ID))
+		if errors.Is(err, gitlab.ErrForbidden) {
+			yellow.Fprintf(os.Stderr, "warning: no access to variables of group %s; skipped\n", g.FullPath)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list variables of group %s: %w", g.FullPath, err)
+		}
+		layers = append(layers, gitlab.Layer{Name: "group:" + g.FullPath, Variables: vars})
+	}
+
+	projectVars, err := client.ListVariables(appCtx, projectID, gitlab.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list project variables: %w", err)
+	}
+	layers = append(layers, gitlab.Layer{Name: "project", Variables: projectVars})
+	return layers, nil
+}
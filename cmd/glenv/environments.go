@@ -0,0 +1,150 @@
+//nolint:errcheck // CLI output errors are intentionally ignored
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/ohmylock/glenv/pkg/gitlab"
+)
+
+// EnvsCommand lists GitLab environments and how many variables apply to each.
+type EnvsCommand struct {
+	global *GlobalOptions
+}
+
+func (cmd *EnvsCommand) Execute(args []string) error {
+	setupColor(cmd.global.NoColor)
+	cfg, client, err := buildClientFromGlobal(cmd.global)
+	if err != nil {
+		return err
+	}
+
+	envs, err := client.ListEnvironments(appCtx, cfg.GitLab.ProjectID)
+	if err != nil {
+		return fmt.Errorf("list environments: %w", err)
+	}
+	vars, err := client.ListVariables(appCtx, cfg.GitLab.ProjectID, gitlab.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list variables: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ENVIRONMENT\tSTATE\tSCOPED\tEFFECTIVE")
+	for _, env := range envs {
+		scoped := 0
+		for _, v := range vars {
+			if v.EnvironmentScope == env.Name {
+				scoped++
+			}
+		}
+		effective := len(gitlab.ResolveScope(vars, env.Name))
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", env.Name, env.State, scoped, effective)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+	fmt.Printf("\nTotal: %d environments\n", len(envs))
+
+	// Variables whose scope matches no environment are never used by any job.
+	orphans := make(map[string]int)
+	for _, v := range vars {
+		if !gitlab.ScopeHasEnvironment(v.EnvironmentScope, envs) {
+			orphans[v.EnvironmentScope]++
+		}
+	}
+	if len(orphans) > 0 {
+		scopes := make([]string, 0, len(orphans))
+		for s := range orphans {
+			scopes = append(scopes, s)
+		}
+		sort.Strings(scopes)
+		yellow.Println("\nScopes matching no environment:")
+		for _, s := range scopes {
+			yellow.Printf("  %s (%d variables)\n", s, orphans[s])
+		}
+	}
+	return nil
+}
+
+// checkScope verifies that scope targets an existing GitLab environment and
+// reports whether scope is a plain name without an environment, which
+// createEnvironment can create.
+//
+// Projects without any environments are not checked. A scope that matches
+// nothing produces a warning; for a plain name strict turns it into an error.
+// Failure to list environments is reported as a warning, never as an error.
+func checkScope(client *gitlab.Client, projectID, scope string, strict bool) (missing bool, err error) {
+	if scope == gitlab.WildcardScope {
+		return false, nil
+	}
+	envs, err := client.ListEnvironments(appCtx, projectID)
+	if err != nil {
+		yellow.Fprintf(os.Stderr, "warning: could not verify environment scope %q: %v\n", scope, err)
+		return false, nil
+	}
+	if len(envs) == 0 || gitlab.ScopeHasEnvironment(scope, envs) {
+		return false, nil
+	}
+
+	if gitlab.IsWildcardScope(scope) {
+		yellow.Fprintf(os.Stderr, "warning: environment scope %q matches no existing environment\n", scope)
+		return false, nil
+	}
+
+	msg := fmt.Sprintf("environment scope %q does not match any GitLab environment", scope)
+	if s := suggestEnvironment(scope, envs); s != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", s)
+	}
+	if strict {
+		return true, fmt.Errorf("%s; pass --create-environment to create it", msg)
+	}
+	yellow.Fprintf(os.Stderr, "warning: %s\n", msg)
+	return true, nil
+}
+
+// createEnvironment creates the GitLab environment named scope.
+func createEnvironment(client *gitlab.Client, projectID, scope string) error {
+	if _, err := client.CreateEnvironment(appCtx, projectID, scope); err != nil {
+		return fmt.Errorf("create environment %s: %w", scope, err)
+	}
+	green.Printf("✓ created environment %s\n", scope)
+	return nil
+}
+
+// suggestEnvironment returns the environment name closest to scope when it is
+// within a small edit distance, or "" if none is close enough.
+func suggestEnvironment(scope string, envs []gitlab.Environment) string {
+	const maxDistance = 2
+	best, bestDist := "", maxDistance+1
+	for _, env := range envs {
+		if d := editDistance(scope, env.Name); d < bestDist {
+			best, bestDist = env.Name, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
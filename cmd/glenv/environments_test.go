@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ohmylock/glenv/pkg/gitlab"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"production", "production", 0},
+		{"prodution", "production", 1},
+		{"stagign", "staging", 2},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestEnvironment(t *testing.T) {
+	envs := []gitlab.Environment{{Name: "production"}, {Name: "staging"}}
+
+	if got := suggestEnvironment("prodution", envs); got != "production" {
+		t.Errorf("suggestEnvironment(prodution) = %q, want production", got)
+	}
+	if got := suggestEnvironment("development", envs); got != "" {
+		t.Errorf("suggestEnvironment(development) = %q, want no suggestion", got)
+	}
+}
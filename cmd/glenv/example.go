@@ -0,0 +1,191 @@
+//nolint:errcheck // CLI output errors are intentionally ignored
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/ohmylock/glenv/pkg/classifier"
+	"github.com/ohmylock/glenv/pkg/config"
+	"github.com/ohmylock/glenv/pkg/envfile"
+	"github.com/ohmylock/glenv/pkg/gitlab"
+)
+
+// ExampleCommand generates a .env.example with placeholder values from a
+// local .env file or from the remote variables.
+type ExampleCommand struct {
+	File           string `short:"f" long:"file" description:"Path to .env file (resolves from config or defaults to .env)"`
+	Environment    string `short:"e" long:"environment" description:"Environment scope" default:"*"`
+	Remote         bool   `long:"remote" description:"Read keys from GitLab instead of a local file"`
+	Output         string `short:"o" long:"output" description:"Output file path (default: stdout)"`
+	NoAutoClassify bool   `long:"no-auto-classify" description:"Disable automatic secret detection"`
+	global         *GlobalOptions
+}
+
+// exampleLine is one line of a generated example: a comment or blank line
+// when key is empty, otherwise a variable.
+type exampleLine struct {
+	key     string
+	comment string
+	secret  string // "masked", "file" or "" when not a secret
+}
+
+func (cmd *ExampleCommand) Execute(args []string) error {
+	var (
+		lines  []exampleLine
+		source string
+	)
+	if cmd.Remote {
+		cfg, client, err := buildClientFromGlobal(cmd.global)
+		if err != nil {
+			return err
+		}
+		vars, err := client.ListVariables(appCtx, cfg.GitLab.ProjectID, gitlab.ListOptions{EnvironmentScope: cmd.Environment})
+		if err != nil {
+			return fmt.Errorf("list variables: %w", err)
+		}
+		vars = gitlab.FilterByScope(vars, cmd.Environment)
+		lines = exampleFromRemote(vars, buildClassifier(cfg, cmd.NoAutoClassify))
+		source = fmt.Sprintf("project %s (%s)", cfg.GitLab.ProjectID, cmd.Environment)
+	} else {
+		cfg, err := config.LoadProfile(cmd.global.Config, cmd.global.Profile)
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+		parsed, from, err := loadLocal(cmd.File, cmd.Environment, cfg, false)
+		if err != nil {
+			return err
+		}
+		lines = exampleFromFile(parsed, buildClassifier(cfg, cmd.NoAutoClassify), cmd.Environment)
+		source = from
+	}
+
+	if cmd.Output == "" {
+		return writeExample(os.Stdout, source, lines)
+	}
+	f, err := os.OpenFile(cmd.Output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644) //nolint:gosec // G302: example files hold no secrets and are meant to be committed
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	if err := writeExample(f, source, lines); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close output file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d keys to %s\n", countKeys(lines), cmd.Output)
+	return nil
+}
+
+// exampleFromFile rebuilds a parsed .env file line by line, keeping comments
+// and blank lines. Keys the parser skipped (placeholders, interpolation) are
+// kept as well; only variables with a value can be checked for secrets.
+func exampleFromFile(parsed *envfile.ParseResult, cl *classifier.Classifier, environment string) []exampleLine {
+	type numbered struct {
+		line int
+		exampleLine
+	}
+	var all []numbered
+	for _, v := range parsed.Variables {
+		all = append(all, numbered{v.Line, exampleLine{key: v.Key, secret: secretKind(cl.Classify(v.Key, v.Value, environment))}})
+	}
+	for _, sk := range parsed.Skipped {
+		switch sk.Reason {
+		case envfile.SkipBlank:
+			all = append(all, numbered{sk.Line, exampleLine{}})
+		case envfile.SkipComment:
+			all = append(all, numbered{sk.Line, exampleLine{comment: sk.Text}})
+		case envfile.SkipPlaceholder, envfile.SkipInterpolation:
+			all = append(all, numbered{sk.Line, exampleLine{key: sk.Key}})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].line < all[j].line })
+
+	seen := make(map[string]bool)
+	lines := make([]exampleLine, 0, len(all))
+	for _, n := range all {
+		if n.key != "" {
+			if seen[n.key] {
+				continue
+			}
+			seen[n.key] = true
+		}
+		lines = append(lines, n.exampleLine)
+	}
+	return lines
+}
+
+// exampleFromRemote lists remote keys in alphabetical order. A key defined in
+// several scopes appears once, marked as secret if any definition is.
+func exampleFromRemote(vars []gitlab.Variable, cl *classifier.Classifier) []exampleLine {
+	secrets := make(map[string]string)
+	for _, v := range vars {
+		kind := secretKind(cl.Classify(v.Key, v.Value, v.EnvironmentScope))
+		switch {
+		case v.VariableType == "file":
+			kind = "file"
+		case v.Masked:
+			kind = "masked"
+		}
+		if _, ok := secrets[v.Key]; !ok || kind != "" {
+			secrets[v.Key] = kind
+		}
+	}
+
+	keys := make([]string, 0, len(secrets))
+	for k := range secrets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	lines := make([]exampleLine, 0, len(keys))
+	for _, k := range keys {
+		lines = append(lines, exampleLine{key: k, secret: secrets[k]})
+	}
+	return lines
+}
+
+func secretKind(cl classifier.Classification) string {
+	switch {
+	case cl.VarType == "file":
+		return "file"
+	case cl.Masked:
+		return "masked"
+	}
+	return ""
+}
+
+// writeExample writes lines as a .env file whose values are all placeholders,
+// so syncing the example by mistake changes nothing.
+func writeExample(w io.Writer, source string, lines []exampleLine) error {
+	if _, err := fmt.Fprintf(w, "# Generated by glenv example from %s.\n# Replace the placeholder values; keys marked secret hold sensitive data.\n\n", source); err != nil {
+		return fmt.Errorf("write example: %w", err)
+	}
+	for _, l := range lines {
+		var err error
+		switch {
+		case l.key == "":
+			_, err = fmt.Fprintln(w, l.comment)
+		case l.secret != "":
+			_, err = fmt.Fprintf(w, "# secret (%s)\n%s=%s\n", l.secret, l.key, envfile.Placeholder(l.key))
+		default:
+			_, err = fmt.Fprintf(w, "%s=%s\n", l.key, envfile.Placeholder(l.key))
+		}
+		if err != nil {
+			return fmt.Errorf("write example: %w", err)
+		}
+	}
+	return nil
+}
+
+func countKeys(lines []exampleLine) int {
+	n := 0
+	for _, l := range lines {
+		if l.key != "" {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ohmylock/glenv/pkg/classifier"
+	"github.com/ohmylock/glenv/pkg/envfile"
+	"github.com/ohmylock/glenv/pkg/gitlab"
+)
+
+func TestExampleFromFile(t *testing.T) {
+	src := `# Database
+DB_HOST=localhost
+DB_PASSWORD=s3cr3tPassw0rd
+
+# API
+API_URL=${BASE_URL}/api
+STRIPE_KEY=your_stripe_key
+`
+	parsed, err := envfile.ParseReader(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	lines := exampleFromFile(parsed, classifier.New(classifier.Rules{}), "*")
+	if err := writeExample(&buf, ".env", lines); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+
+	want := `# Database
+DB_HOST=your_db_host
+# secret (masked)
+DB_PASSWORD=your_db_password
+
+# API
+API_URL=your_api_url
+STRIPE_KEY=your_stripe_key
+`
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("example output:\n%s\nwant suffix:\n%s", got, want)
+	}
+
+	// The generated file must not sync anything.
+	reparsed, err := envfile.ParseReader(strings.NewReader(got))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reparsed.Variables) != 0 {
+		t.Errorf("example parses to %d variables, want 0", len(reparsed.Variables))
+	}
+}
+
+func TestExampleFromRemote(t *testing.T) {
+	vars := []gitlab.Variable{
+		{Key: "TLS_CERT", Value: "x", VariableType: "file", EnvironmentScope: "*"},
+		{Key: "API_TOKEN", Value: "plain", EnvironmentScope: "staging"},
+		{Key: "API_TOKEN", Value: "abcdefgh12345678", Masked: true, EnvironmentScope: "production"},
+		{Key: "LOG_LEVEL", Value: "info", EnvironmentScope: "*"},
+	}
+
+	lines := exampleFromRemote(vars, classifier.NewEmpty())
+	want := []exampleLine{
+		{key: "API_TOKEN", secret: "masked"},
+		{key: "LOG_LEVEL"},
+		{key: "TLS_CERT", secret: "file"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d: %+v", len(lines), len(want), lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, lines[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,115 @@
+//nolint:errcheck // CLI output errors are intentionally ignored
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/ohmylock/glenv/pkg/config"
+	"github.com/ohmylock/glenv/pkg/envfile"
+)
+
+// LintCommand reports problems in .env files that the parser tolerates silently.
+type LintCommand struct {
+	Environment string `short:"e" long:"environment" description:"Lint the file configured for this environment" default:"*"`
+	All         bool   `short:"a" long:"all" description:"Lint the files of all environments defined in config"`
+	Fix         bool   `long:"fix" description:"Rewrite files to resolve fixable issues"`
+	global      *GlobalOptions
+}
+
+func (cmd *LintCommand) Execute(args []string) error {
+	setupColor(cmd.global.NoColor)
+
+	files := args
+	if len(files) == 0 {
+		cfg, err := config.LoadProfile(cmd.global.Config, cmd.global.Profile)
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+		files, err = lintTargets(cfg, cmd.Environment, cmd.All)
+		if err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, file := range files {
+		if err := cmd.lintFile(file); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// lintTargets returns the .env files to lint when none are given on the command line.
+func lintTargets(cfg *config.Config, environment string, all bool) ([]string, error) {
+	if !all {
+		return []string{resolveEnvFile("", environment, cfg)}, nil
+	}
+	if len(cfg.Environments) == 0 {
+		return nil, fmt.Errorf("--all requires environments to be defined in config file")
+	}
+	seen := make(map[string]bool)
+	var files []string
+	for name := range cfg.Environments {
+		f := resolveEnvFile("", name, cfg)
+		if !seen[f] {
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (cmd *LintCommand) lintFile(file string) error {
+	data, err := os.ReadFile(file) //nolint:gosec // G304: file path comes from user CLI argument, expected behavior
+	if err != nil {
+		return fmt.Errorf("read %s: %w", file, err)
+	}
+
+	issues, err := envfile.Lint(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if cmd.Fix && hasFixable(issues) {
+		fixed, remaining, err := envfile.Fix(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("stat %s: %w", file, err)
+		}
+		if err := os.WriteFile(file, fixed, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("write %s: %w", file, err)
+		}
+		green.Printf("✓ %s: fixed %d issue(s)\n", file, len(issues)-len(remaining))
+		issues = remaining
+	}
+
+	if len(issues) == 0 {
+		green.Printf("✓ %s: no issues\n", file)
+		return nil
+	}
+	for _, issue := range issues {
+		hint := ""
+		if issue.Fixable && !cmd.Fix {
+			hint = " (fixable with --fix)"
+		}
+		red.Printf("%s:%d: %s: %s%s\n", file, issue.Line, issue.Rule, issue.Message, hint)
+	}
+	return fmt.Errorf("%s: %d issue(s)", file, len(issues))
+}
+
+func hasFixable(issues []envfile.LintIssue) bool {
+	for _, i := range issues {
+		if i.Fixable {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLintFile_Fix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("A=1\r\nA=2  \r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &LintCommand{Fix: true, global: &GlobalOptions{NoColor: true}}
+	if err := cmd.lintFile(path); err != nil {
+		t.Fatalf("lintFile(--fix) = %v, want all issues fixed", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "A=2\n" {
+		t.Errorf("fixed file = %q, want %q", got, "A=2\n")
+	}
+
+	if err := os.WriteFile(path, []byte("BAD-KEY=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.lintFile(path); err == nil {
+		t.Error("lintFile: expected error for unfixable issue")
+	}
+}
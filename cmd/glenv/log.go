@@ -0,0 +1,54 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Log formats for --log-format.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// secretAttrs are attribute keys whose values are always redacted, as a
+// safety net on top of the LogValue methods of the gitlab types.
+var secretAttrs = map[string]bool{
+	"token":         true,
+	"private_token": true,
+	"access_token":  true,
+	"refresh_token": true,
+	"authorization": true,
+	"value":         true,
+}
+
+// logger returns the structured logger selected by --verbose, --debug and
+// --log-format. Without --verbose or --debug nothing is logged.
+func (g *GlobalOptions) logger() *slog.Logger {
+	return newLogger(os.Stderr, g.Verbose, g.Debug, g.LogFormat)
+}
+
+func newLogger(w io.Writer, verbose, debug bool, format string) *slog.Logger {
+	if !verbose && !debug {
+		return slog.New(slog.DiscardHandler)
+	}
+	level := slog.LevelInfo
+	if debug {
+		level = slog.LevelDebug
+	}
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr}
+	if format == logFormatJSON {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// redactAttr replaces the value of attributes named like secrets.
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if secretAttrs[strings.ToLower(a.Key)] && a.Value.Kind() != slog.KindGroup {
+		return slog.String(a.Key, "[REDACTED]")
+	}
+	return a
+}
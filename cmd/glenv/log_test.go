@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ohmylock/glenv/pkg/gitlab"
+)
+
+func TestNewLogger_Levels(t *testing.T) {
+	var buf bytes.Buffer
+	newLogger(&buf, false, false, logFormatText).Warn("quiet")
+	if buf.Len() != 0 {
+		t.Errorf("logging without -v or --debug wrote %q", buf.String())
+	}
+
+	newLogger(&buf, true, false, logFormatText).Debug("hidden")
+	newLogger(&buf, true, false, logFormatText).Info("shown")
+	if strings.Contains(buf.String(), "hidden") || !strings.Contains(buf.String(), "shown") {
+		t.Errorf("-v output = %q, want info records only", buf.String())
+	}
+
+	buf.Reset()
+	newLogger(&buf, false, true, logFormatText).Debug("request")
+	if !strings.Contains(buf.String(), "request") {
+		t.Errorf("--debug output = %q, want debug records", buf.String())
+	}
+}
+
+func TestNewLogger_JSONRedacts(t *testing.T) {
+	var buf bytes.Buffer
+	log := newLogger(&buf, false, true, logFormatJSON)
+	log.Debug("create",
+		slog.Any("request", gitlab.CreateRequest{Key: "API_KEY", Value: "s3cret-value"}),
+		slog.Any("auth", gitlab.PrivateToken("glpat-abcdefghijklmnop")),
+		slog.String("token", "glpat-abcdefghijklmnop"),
+	)
+
+	out := buf.String()
+	for _, secret := range []string{"s3cret-value", "glpat-abcdefghijklmnop"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log output leaks %q: %s", secret, out)
+		}
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(out), &record); err != nil {
+		t.Fatalf("output is not JSON: %v: %s", err, out)
+	}
+	if req, _ := record["request"].(map[string]any); req["key"] != "API_KEY" {
+		t.Errorf("request = %v, want key API_KEY", record["request"])
+	}
+}
@@ -7,10 +7,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"os/signal"
+	"path"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"syscall"
 	"text/tabwriter"
 	"time"
@@ -21,6 +26,9 @@ import (
 	"github.com/ohmylock/glenv/pkg/config"
 	"github.com/ohmylock/glenv/pkg/envfile"
 	"github.com/ohmylock/glenv/pkg/gitlab"
+	"github.com/ohmylock/glenv/pkg/hooks"
+	"github.com/ohmylock/glenv/pkg/refs"
+	"github.com/ohmylock/glenv/pkg/source"
 	glsync "github.com/ohmylock/glenv/pkg/sync"
 )
 
@@ -42,13 +50,18 @@ var (
 // GlobalOptions holds flags shared across all commands.
 type GlobalOptions struct {
 	Config    string  `short:"c" long:"config" description:"Path to .glenv.yml config file"`
-	Token     string  `long:"token" env:"GITLAB_TOKEN" description:"GitLab private token"`
-	Project   string  `long:"project" env:"GITLAB_PROJECT_ID" description:"GitLab project ID"`
-	URL       string  `long:"url" env:"GITLAB_URL" description:"GitLab base URL"`
+	Profile   string  `long:"profile" description:"Config profile to use (default: GLENV_PROFILE or profile in config)"`
+	Verbose   bool    `short:"v" long:"verbose" description:"Print details such as rate limit adjustments, retries and failed requests"`
+	Debug     bool    `long:"debug" description:"Log every API request and sync task"`
+	LogFormat string  `long:"log-format" description:"Format of -v/--debug log records" choice:"text" choice:"json" default:"text"`
+	Token     string  `long:"token" description:"GitLab private token (default: GITLAB_TOKEN or config)"`
+	Project   string  `long:"project" description:"GitLab project ID (default: GITLAB_PROJECT_ID or config)"`
+	URL       string  `long:"url" description:"GitLab base URL (default: GITLAB_URL or config)"`
 	DryRun    bool    `short:"n" long:"dry-run" description:"Print planned changes without applying them"`
 	NoColor   bool    `long:"no-color" description:"Disable colored output"`
 	Workers   int     `short:"w" long:"workers" description:"Number of concurrent workers"`
 	RateLimit float64 `long:"rate-limit" description:"Max API requests per second"`
+	Schema    string  `long:"schema" description:"Path to variable schema file (default: schema section in config or .glenv.schema.yml)"`
 }
 
 // VersionCommand prints the build version.
@@ -61,13 +74,21 @@ func (cmd *VersionCommand) Execute(args []string) error {
 
 // SyncCommand pushes local .env variables to GitLab.
 type SyncCommand struct {
-	File          string `short:"f" long:"file" description:"Path to .env file (resolves from config or defaults to .env)"`
-	Environment   string `short:"e" long:"environment" description:"GitLab environment scope" default:"*"`
-	All           bool   `short:"a" long:"all" description:"Sync all environments defined in config"`
-	DeleteMissing bool   `long:"delete-missing" description:"Delete remote variables not present in .env file"`
-	NoAutoClassify bool  `long:"no-auto-classify" description:"Disable automatic variable classification"`
-	Force         bool   `long:"force" description:"Skip confirmation prompt"`
-	global        *GlobalOptions
+	File           string `short:"f" long:"file" description:"Path to .env file (resolves from config or defaults to .env)"`
+	Environment    string `short:"e" long:"environment" description:"GitLab environment scope" default:"*"`
+	All            bool   `short:"a" long:"all" description:"Sync all environments defined in config"`
+	DeleteMissing  bool   `long:"delete-missing" description:"Delete remote variables not present in .env file"`
+	NoAutoClassify bool   `long:"no-auto-classify" description:"Disable automatic variable classification"`
+	Force          bool   `long:"force" description:"Skip confirmation prompt"`
+	ForceOverwrite bool   `long:"force-overwrite" description:"Overwrite variables even if they changed remotely since the diff"`
+	CreateEnv      bool   `long:"create-environment" description:"Create the GitLab environment if the target scope matches none"`
+	StrictEnvs     bool   `long:"strict-environments" description:"Fail if the target scope matches no GitLab environment (ignored with --dry-run)"`
+	Trigger        bool   `long:"trigger-pipeline" description:"Trigger a pipeline after a sync that changed variables"`
+	NoTrigger      bool   `long:"no-trigger-pipeline" description:"Do not trigger a pipeline even if enabled in config"`
+	Ref            string `long:"ref" description:"Ref to run the triggered pipeline on (default: pipeline.ref or the default branch)"`
+	Wait           bool   `long:"wait" description:"Wait for the triggered pipeline to finish"`
+	NoResolve      bool   `long:"no-resolve" description:"Sync ref+ value references as written instead of resolving them"`
+	global         *GlobalOptions
 }
 
 func (cmd *SyncCommand) Execute(args []string) error {
@@ -91,9 +112,8 @@ func (cmd *SyncCommand) Execute(args []string) error {
 
 		var errs []error
 		for _, envName := range envNames {
-			envFile := resolveEnvFile(cmd.File, envName, cfg)
-			fmt.Printf("\n=== Syncing environment: %s (file: %s) ===\n", envName, envFile)
-			if err := cmd.syncOne(cfg, client, envFile, envName); err != nil {
+			fmt.Printf("\n=== Syncing environment: %s ===\n", envName)
+			if err := cmd.syncOne(cfg, client, envName); err != nil {
 				red.Printf("error syncing %s: %v\n", envName, err)
 				errs = append(errs, fmt.Errorf("%s: %w", envName, err))
 			}
@@ -101,21 +121,51 @@ func (cmd *SyncCommand) Execute(args []string) error {
 		return errors.Join(errs...)
 	}
 
-	return cmd.syncOne(cfg, client, resolveEnvFile(cmd.File, cmd.Environment, cfg), cmd.Environment)
+	return cmd.syncOne(cfg, client, cmd.Environment)
 }
 
-// syncOne performs a single sync of envFile to the given environment scope.
-func (cmd *SyncCommand) syncOne(cfg *config.Config, client *gitlab.Client, envFile, envScope string) error {
-	parsed, err := envfile.ParseFile(envFile)
+// syncOne performs a single sync of the local variables for envScope (see
+// resolveSource) to the given environment scope.
+func (cmd *SyncCommand) syncOne(cfg *config.Config, client *gitlab.Client, envScope string) error {
+	parsed, envFile, err := loadLocal(cmd.File, envScope, cfg, !cmd.NoResolve)
+	if err != nil {
+		return err
+	}
+
+	s, err := loadSchema(cfg, cmd.global.Schema)
 	if err != nil {
-		return fmt.Errorf("parse %s: %w", envFile, err)
+		return err
+	}
+	if err := checkSchema(s, parsed, envScope, envFile); err != nil {
+		return err
+	}
+
+	strict := cmd.StrictEnvs && !cmd.CreateEnv && !cmd.global.DryRun
+	missingEnv, err := checkScope(client, cfg.GitLab.ProjectID, envScope, strict)
+	if err != nil {
+		return err
+	}
+
+	var runner hooks.Runner
+	payload := hooks.Payload{
+		ProjectID:   cfg.GitLab.ProjectID,
+		Environment: envScope,
+		File:        envFile,
+		DryRun:      cmd.global.DryRun,
+	}
+	pre := payload
+	pre.Stage, pre.Variables = hooks.PreDiff, hooks.NewVariables(parsed.Variables)
+	if err := runner.Run(appCtx, cfg.Hooks.PreDiff, pre); err != nil {
+		return err
 	}
 
 	cl := buildClassifier(cfg, cmd.NoAutoClassify)
 	opts := glsync.Options{
-		Workers:       resolveWorkers(cmd.global, cfg),
-		DryRun:        cmd.global.DryRun,
-		DeleteMissing: cmd.DeleteMissing,
+		Workers:         resolveWorkers(cmd.global, cfg),
+		DryRun:          cmd.global.DryRun,
+		DeleteMissing:   cmd.DeleteMissing,
+		DetectConflicts: !cmd.ForceOverwrite,
+		Logger:          cmd.global.logger(),
 	}
 	engine := glsync.NewEngine(client, cl, opts, cfg.GitLab.ProjectID)
 
@@ -132,31 +182,51 @@ func (cmd *SyncCommand) syncOne(cfg *config.Config, client *gitlab.Client, envFi
 		return nil
 	}
 	// Only prompt when --delete-missing would actually delete variables.
-	if cmd.DeleteMissing && !cmd.Force {
-		deleteCount := 0
-		for _, ch := range diff.Changes {
-			if ch.Kind == glsync.ChangeDelete {
-				deleteCount++
-			}
-		}
-		if deleteCount > 0 {
-			if !confirm(fmt.Sprintf("Delete %d variable(s)?", deleteCount)) {
-				fmt.Println("Aborted.")
-				return nil
-			}
+	if cmd.DeleteMissing && !cmd.Force && !confirmDeletes(diff) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	payload.Changes = hooks.NewChanges(diff)
+	pre = payload
+	pre.Stage = hooks.PreApply
+	if err := runner.Run(appCtx, cfg.Hooks.PreApply, pre); err != nil {
+		return err
+	}
+
+	if missingEnv && cmd.CreateEnv {
+		if err := createEnvironment(client, cfg.GitLab.ProjectID, envScope); err != nil {
+			return err
 		}
 	}
 
 	fmt.Printf("\nSyncing: %s → project %s (%s)\n", envFile, cfg.GitLab.ProjectID, envScope)
 	fmt.Println(separator)
 	fmt.Println()
+	var changed []string
 	report := engine.ApplyWithCallback(appCtx, diff, func(r glsync.Result) {
 		printResult(r)
+		if r.Error == nil && r.Change.Kind != glsync.ChangeUnchanged && r.Change.Kind != glsync.ChangeSkipped {
+			changed = append(changed, r.Change.Key)
+		}
 	})
 
 	printSyncReport(report)
+
+	post := payload
+	post.Stage, post.Report = hooks.PostApply, hooks.NewReport(report)
+	hookErr := runner.Run(appCtx, cfg.Hooks.PostApply, post)
 	if report.Failed > 0 {
-		return fmt.Errorf("%d variable(s) failed to sync", report.Failed)
+		return errors.Join(fmt.Errorf("%d variable(s) failed to sync", report.Failed), hookErr)
+	}
+	if hookErr != nil {
+		return hookErr
+	}
+
+	if (cfg.Pipeline.Trigger || cmd.Trigger) && !cmd.NoTrigger && len(changed) > 0 {
+		fmt.Println()
+		run := newPipelineRun(cfg.Pipeline, cmd.Ref, cmd.Wait)
+		return runPipeline(client, cfg.GitLab.ProjectID, run, syncPipelineVariables(envScope, changed, report))
 	}
 	return nil
 }
@@ -166,6 +236,7 @@ type DiffCommand struct {
 	File          string `short:"f" long:"file" description:"Path to .env file (resolves from config or defaults to .env)"`
 	Environment   string `short:"e" long:"environment" description:"GitLab environment scope" default:"*"`
 	DeleteMissing bool   `long:"delete-missing" description:"Show variables that would be deleted"`
+	NoResolve     bool   `long:"no-resolve" description:"Diff ref+ value references as written instead of resolving them"`
 	global        *GlobalOptions
 }
 
@@ -176,10 +247,22 @@ func (cmd *DiffCommand) Execute(args []string) error {
 		return err
 	}
 
-	envFile := resolveEnvFile(cmd.File, cmd.Environment, cfg)
-	parsed, err := envfile.ParseFile(envFile)
+	parsed, envFile, err := loadLocal(cmd.File, cmd.Environment, cfg, !cmd.NoResolve)
 	if err != nil {
-		return fmt.Errorf("parse %s: %w", envFile, err)
+		return err
+	}
+
+	// diff is a preview: report schema violations without failing.
+	s, err := loadSchema(cfg, cmd.global.Schema)
+	if err != nil {
+		return err
+	}
+	if checkSchema(s, parsed, cmd.Environment, envFile) != nil {
+		fmt.Println()
+	}
+
+	if _, err := checkScope(client, cfg.GitLab.ProjectID, cmd.Environment, false); err != nil {
+		return err
 	}
 
 	cl := buildClassifier(cfg, false)
@@ -314,15 +397,26 @@ func (cmd *ExportCommand) Execute(args []string) error {
 
 // DeleteCommand removes one or more remote variables.
 type DeleteCommand struct {
-	Environment string `short:"e" long:"environment" description:"Environment scope of variable to delete"`
-	Force       bool   `long:"force" description:"Skip confirmation prompt"`
+	Environment string   `short:"e" long:"environment" description:"Environment scope of variable to delete"`
+	Match       []string `long:"match" description:"Delete variables whose key matches this glob pattern (repeatable)"`
+	Scope       string   `long:"scope" description:"With --match: only delete variables whose scope is or matches this glob pattern ('*' selects the default scope only)"`
+	Force       bool     `long:"force" description:"Skip confirmation prompt"`
 	global      *GlobalOptions
 }
 
 func (cmd *DeleteCommand) Execute(args []string) error {
 	setupColor(cmd.global.NoColor)
+	if len(cmd.Match) > 0 {
+		if len(args) > 0 {
+			return fmt.Errorf("pass either KEY arguments or --match, not both")
+		}
+		if cmd.Environment != "" && cmd.Scope != "" {
+			return fmt.Errorf("pass either -e or --scope with --match, not both")
+		}
+		return cmd.deleteMatching()
+	}
 	if len(args) == 0 {
-		return fmt.Errorf("usage: glenv delete [KEY...]")
+		return fmt.Errorf("usage: glenv delete [KEY...] | glenv delete --match PATTERN [--scope PATTERN]")
 	}
 
 	cfg, client, err := buildClientFromGlobal(cmd.global)
@@ -358,8 +452,104 @@ func (cmd *DeleteCommand) Execute(args []string) error {
 	return nil
 }
 
+// deleteMatching resolves remote variables matching --match/--scope, previews
+// them and deletes them concurrently through the sync engine.
+func (cmd *DeleteCommand) deleteMatching() error {
+	cfg, client, err := buildClientFromGlobal(cmd.global)
+	if err != nil {
+		return err
+	}
+
+	vars, err := client.ListVariables(appCtx, cfg.GitLab.ProjectID, gitlab.ListOptions{EnvironmentScope: cmd.Environment})
+	if err != nil {
+		return fmt.Errorf("list variables: %w", err)
+	}
+	if cmd.Environment != "" {
+		// -e names one exact scope, as for KEY arguments.
+		vars = slices.DeleteFunc(vars, func(v gitlab.Variable) bool { return v.EnvironmentScope != cmd.Environment })
+	}
+	matched, err := matchVariables(vars, cmd.Match, cmd.Scope)
+	if err != nil {
+		return err
+	}
+	if len(matched) == 0 {
+		fmt.Println("No variables match.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tSCOPE\tTYPE")
+	for _, v := range matched {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key, v.EnvironmentScope, v.VariableType)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+	fmt.Printf("\nMatched: %d variables\n", len(matched))
+
+	if cmd.global.DryRun {
+		return nil
+	}
+	if !cmd.Force && !confirm("Confirm deletion?") {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	opts := glsync.Options{Workers: resolveWorkers(cmd.global, cfg), Logger: cmd.global.logger()}
+	engine := glsync.NewEngine(client, classifier.NewEmpty(), opts, cfg.GitLab.ProjectID)
+	fmt.Println()
+	report := engine.ApplyWithCallback(appCtx, glsync.DeleteChanges(matched), func(r glsync.Result) {
+		printResult(r)
+	})
+
+	printSyncReport(report)
+	if report.Failed > 0 {
+		return fmt.Errorf("%d deletion(s) failed", report.Failed)
+	}
+	return nil
+}
+
 // --- Helpers ---
 
+// matchVariables returns the variables whose key matches any of keyPatterns
+// and, if scopePattern is set, whose environment scope matches it. Patterns use
+// path.Match syntax. A scope always matches itself literally, so "review/*"
+// selects the "review/*" scope as well as "review/feature-x"; the pattern "*"
+// selects only GitLab's default "*" scope rather than every scope without a
+// slash.
+func matchVariables(vars []gitlab.Variable, keyPatterns []string, scopePattern string) ([]gitlab.Variable, error) {
+	for _, p := range append(slices.Clone(keyPatterns), scopePattern) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+
+	var matched []gitlab.Variable
+	for _, v := range vars {
+		if scopePattern != "" && !scopeSelected(scopePattern, v.EnvironmentScope) {
+			continue
+		}
+		for _, p := range keyPatterns {
+			if ok, _ := path.Match(p, v.Key); ok {
+				matched = append(matched, v)
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+func scopeSelected(pattern, scope string) bool {
+	if scope == pattern {
+		return true
+	}
+	if pattern == "*" {
+		return false
+	}
+	ok, _ := path.Match(pattern, scope)
+	return ok
+}
+
 // resolveWorkers returns the number of workers: CLI flag if set, else config, else default 5.
 func resolveWorkers(global *GlobalOptions, cfg *config.Config) int {
 	if global.Workers > 0 {
@@ -385,41 +575,174 @@ func resolveEnvFile(flagFile, environment string, cfg *config.Config) string {
 	return ".env"
 }
 
+// resolveSource returns where to read local variables from, using priority:
+// explicit --file flag > environment source from config > .env file as
+// resolved by resolveEnvFile. A Vault source uses the TLS, proxy and timeout
+// settings of the http section.
+func resolveSource(flagFile, environment string, cfg *config.Config) (source.Source, error) {
+	if flagFile == "" && environment != "*" {
+		if envCfg, ok := cfg.Environments[environment]; ok && envCfg.Source != nil {
+			src, err := source.New(*envCfg.Source)
+			if err != nil {
+				return nil, err
+			}
+			if v, ok := src.(*source.Vault); ok {
+				if v.HTTP, err = newHTTPClient(cfg); err != nil {
+					return nil, err
+				}
+			}
+			return src, nil
+		}
+	}
+	return source.File{Path: resolveEnvFile(flagFile, environment, cfg)}, nil
+}
+
+// loadLocal reads the local variables for environment and returns them
+// together with a description of where they came from. With resolve set,
+// ref+ value references are replaced by the values they point to.
+func loadLocal(flagFile, environment string, cfg *config.Config, resolve bool) (*envfile.ParseResult, string, error) {
+	src, err := resolveSource(flagFile, environment, cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	parsed, err := loadSource(src, resolve)
+	if err != nil {
+		return nil, "", err
+	}
+	return parsed, src.String(), nil
+}
+
+// loadSource reads the variables of src and optionally resolves references.
+// Only local .env files are resolved: values from Vault, a directory or a
+// command are secrets themselves and must not be able to run ref+cmd://
+// commands. File references are relative to the .env file's directory.
+func loadSource(src source.Source, resolve bool) (*envfile.ParseResult, error) {
+	parsed, err := src.Load(appCtx)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", src, err)
+	}
+	file, ok := src.(source.File)
+	if !resolve || !ok {
+		return parsed, nil
+	}
+	resolved, err := refs.Resolver{Dir: filepath.Dir(file.Path)}.Resolve(appCtx, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("resolve references in %s: %w", src, err)
+	}
+	return resolved, nil
+}
+
 func buildClientFromGlobal(global *GlobalOptions) (*config.Config, *gitlab.Client, error) {
-	cfg, err := config.Load(global.Config)
+	cfg, err := loadGlobalConfig(global)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	client, err := newClient(cfg, global)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, client, nil
+}
+
+// loadGlobalConfig loads the config, applies the global flags and resolves
+// the token through the provider chain if none was set explicitly.
+func loadGlobalConfig(global *GlobalOptions) (*config.Config, error) {
+	cfg, err := config.LoadProfile(global.Config, global.Profile)
 	if err != nil {
-		return nil, nil, fmt.Errorf("load config: %w", err)
+		return nil, fmt.Errorf("load config: %w", err)
 	}
 
-	// CLI flags override config file values.
+	// CLI flags override config file and environment values; config.Load
+	// has already applied GITLAB_TOKEN, GITLAB_PROJECT_ID and GITLAB_URL.
 	if global.Token != "" {
 		cfg.GitLab.Token = global.Token
+		cfg.GitLab.TokenSource = config.TokenSourceFlag
 	}
 	if global.Project != "" {
 		cfg.GitLab.ProjectID = global.Project
+		cfg.SetOrigin("gitlab.project_id", "--project flag")
 	}
 	if global.URL != "" {
 		cfg.GitLab.URL = global.URL
+		cfg.SetOrigin("gitlab.url", "--url flag")
+	}
+	if global.RateLimit > 0 {
+		cfg.RateLimit.RequestsPerSecond = global.RateLimit
+		cfg.SetOrigin("rate_limit.requests_per_second", "--rate-limit flag")
 	}
 
-	if err := cfg.Validate(); err != nil {
-		return nil, nil, err
+	if err := cfg.ResolveToken(appCtx); err != nil {
+		return nil, err
+	}
+	if cfg.GitLab.Auth == config.AuthOAuth && cfg.GitLab.Token == "" {
+		token, err := loadOAuthToken(cfg.GitLab.URL)
+		if err != nil {
+			return nil, err
+		}
+		if token != nil {
+			cfg.GitLab.Token = token.AccessToken
+			cfg.GitLab.TokenSource = tokenSourceOAuthLogin
+		}
 	}
+	return cfg, nil
+}
 
-	rps := global.RateLimit
-	if rps <= 0 {
-		rps = cfg.RateLimit.RequestsPerSecond
+// newClient builds the GitLab client from cfg, including the TLS, proxy and
+// timeout settings of the http section and the authentication mode. With
+// -v or --debug, rate limit adjustments, retries and requests are logged to
+// stderr.
+func newClient(cfg *config.Config, global *GlobalOptions) (*gitlab.Client, error) {
+	httpClient, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := newAuthenticator(cfg, httpClient)
+	if err != nil {
+		return nil, err
 	}
-	clientCfg := gitlab.ClientConfig{
+
+	rps := cfg.RateLimit.RequestsPerSecond
+	return gitlab.NewClient(gitlab.ClientConfig{
 		BaseURL:             cfg.GitLab.URL,
 		Token:               cfg.GitLab.Token,
 		RequestsPerSecond:   rps,
 		Burst:               max(1, int(rps)),
 		RetryMax:            cfg.RateLimit.RetryMax,
 		RetryInitialBackoff: cfg.RateLimit.RetryInitialBackoff,
-	}
+		RetryBudget:         cfg.RateLimit.RetryBudget,
+		BreakerThreshold:    cfg.RateLimit.BreakerThreshold,
+		BreakerCooldown:     cfg.RateLimit.BreakerCooldown,
+		HTTPClient:          httpClient,
+		Auth:                auth,
+		Logger:              global.logger(),
+	}), nil
+}
 
-	return cfg, gitlab.NewClient(clientCfg), nil
+// insecureWarning makes newHTTPClient warn about insecure_skip_verify once,
+// even when it builds clients for both GitLab and a Vault source.
+var insecureWarning sync.Once
+
+// newHTTPClient builds the HTTP client from the http section of cfg.
+func newHTTPClient(cfg *config.Config) (*http.Client, error) {
+	if cfg.HTTP.InsecureSkipVerify {
+		insecureWarning.Do(func() {
+			yellow.Fprintln(os.Stderr, "WARNING: TLS certificate verification is disabled (http.insecure_skip_verify).")
+			yellow.Fprintln(os.Stderr, "WARNING: tokens and variable values can be intercepted.")
+		})
+	}
+	return gitlab.NewHTTPClient(gitlab.HTTPConfig{
+		CACertFile:          cfg.HTTP.CACert,
+		ClientCertFile:      cfg.HTTP.ClientCert,
+		ClientKeyFile:       cfg.HTTP.ClientKey,
+		InsecureSkipVerify:  cfg.HTTP.InsecureSkipVerify,
+		ProxyURL:            cfg.HTTP.Proxy,
+		Timeout:             cfg.HTTP.Timeout,
+		DialTimeout:         cfg.HTTP.DialTimeout,
+		TLSHandshakeTimeout: cfg.HTTP.TLSHandshakeTimeout,
+	})
 }
 
 func buildClassifier(cfg *config.Config, noAutoClassify bool) *classifier.Classifier {
@@ -461,6 +784,21 @@ func confirm(prompt string) bool {
 	return false
 }
 
+// confirmDeletes prompts for confirmation when diff contains deletions.
+// It returns true when there is nothing to delete or the user agreed.
+func confirmDeletes(diff glsync.DiffResult) bool {
+	deleteCount := 0
+	for _, ch := range diff.Changes {
+		if ch.Kind == glsync.ChangeDelete {
+			deleteCount++
+		}
+	}
+	if deleteCount == 0 {
+		return true
+	}
+	return confirm(fmt.Sprintf("Delete %d variable(s)?", deleteCount))
+}
+
 func maskIfNeeded(value, classification string) string {
 	if strings.Contains(classification, "masked") {
 		return "***"
@@ -469,6 +807,10 @@ func maskIfNeeded(value, classification string) string {
 }
 
 func printResult(r glsync.Result) {
+	if errors.Is(r.Error, gitlab.ErrCircuitOpen) {
+		red.Printf("  ✗ Failed:    %-30s (not sent, circuit breaker open)\n", r.Change.Key)
+		return
+	}
 	if r.Error != nil {
 		red.Printf("  ✗ Failed:    %-30s (%v)\n", r.Change.Key, r.Error)
 		return
@@ -547,14 +889,49 @@ func printSyncReport(report glsync.SyncReport) {
 		rate = float64(report.APICalls) / report.Duration.Seconds()
 	}
 	fmt.Printf("  Duration: %s | API calls: %d | Rate: %.1f req/s\n", dur, report.APICalls, rate)
+	if rl := report.RateLimit; rl != nil && (rl.Limit > 0 || rl.Adjustments > 0) {
+		line := fmt.Sprintf("  Rate limit: %.1f/%.1f req/s", rl.Rate, rl.MaxRate)
+		if rl.Limit > 0 {
+			line += fmt.Sprintf(" | Remaining: %d/%d", rl.Remaining, rl.Limit)
+		}
+		if rl.Throttled() {
+			yellow.Println(line + " (throttled)")
+		} else {
+			fmt.Println(line)
+		}
+	}
+	if br := report.Breaker; br != nil && br.Trips > 0 {
+		yellow.Printf("  Circuit breaker: %s | Tripped: %d time(s) | Last failure: %s\n", br.State, br.Trips, br.LastError)
+	}
 	fmt.Println(separator)
 
-	if len(report.Errors) > 0 {
+	errs, skipped := splitCircuitErrors(report.Errors)
+	if len(errs) > 0 || skipped > 0 {
 		fmt.Println("\nErrors:")
-		for _, e := range report.Errors {
+		for _, e := range errs {
 			red.Printf("  %v\n", e)
 		}
+		if skipped > 0 {
+			red.Printf("  %d variable(s) not sent: GitLab looks unavailable (circuit breaker open)\n", skipped)
+		}
 	}
+	if report.Conflicts > 0 {
+		yellow.Printf("\n%d variable(s) changed remotely since the diff and were left untouched.\n", report.Conflicts)
+		fmt.Println("Re-run to review the new state, or pass --force-overwrite to overwrite them.")
+	}
+}
+
+// splitCircuitErrors separates the errors of requests refused by the open
+// circuit breaker, which all say the same, from the other errors.
+func splitCircuitErrors(all []error) (errs []error, circuitOpen int) {
+	for _, e := range all {
+		if errors.Is(e, gitlab.ErrCircuitOpen) {
+			circuitOpen++
+			continue
+		}
+		errs = append(errs, e)
+	}
+	return errs, circuitOpen
 }
 
 func buildTags(classification string) string {
@@ -591,9 +968,58 @@ func main() {
 	listCmd := &ListCommand{global: global}
 	parser.AddCommand("list", "List variables", "List all GitLab CI/CD variables", listCmd)
 
+	resolveCmd := &ResolveCommand{global: global}
+	parser.AddCommand("resolve", "Resolve variables for an environment", "Show the value each job in an environment sees, applying GitLab scope precedence", resolveCmd)
+
+	effectiveCmd := &EffectiveCommand{global: global}
+	parser.AddCommand("effective", "Show effective variables", "Resolve variables across instance, group and project layers for an environment", effectiveCmd)
+
+	compareCmd := &CompareCommand{global: global}
+	parser.AddCommand("compare", "Compare two environments", "Compare the variables of two environments or projects side by side", compareCmd)
+
 	exportCmd := &ExportCommand{global: global}
 	parser.AddCommand("export", "Export variables", "Export GitLab CI/CD variables as KEY=VALUE", exportCmd)
 
+	exampleCmd := &ExampleCommand{global: global}
+	parser.AddCommand("example", "Generate .env.example", "Generate a .env.example with placeholder values from a local file or the remote variables", exampleCmd)
+
+	planCmd := &PlanCommand{global: global}
+	parser.AddCommand("plan", "Save a plan", "Compute the diff and save it to a plan file for later apply", planCmd)
+
+	applyCmd := &ApplyCommand{global: global}
+	parser.AddCommand("apply", "Apply a plan", "Apply a plan file created by glenv plan", applyCmd)
+
+	getCmd := &GetCommand{global: global}
+	parser.AddCommand("get", "Get a variable", "Print the value of a single GitLab CI/CD variable", getCmd)
+
+	setCmd := &SetCommand{global: global}
+	parser.AddCommand("set", "Set a variable", "Create or update a single GitLab CI/CD variable", setCmd)
+
+	mvCmd := &MoveCommand{global: global}
+	parser.AddCommand("mv", "Rename a variable", "Rename a GitLab CI/CD variable, preserving value, type and flags", mvCmd)
+
+	rescopeCmd := &RescopeCommand{global: global}
+	parser.AddCommand("rescope", "Move a variable to another scope", "Move a GitLab CI/CD variable to another environment scope", rescopeCmd)
+
+	runPipelineCmd := &RunPipelineCommand{global: global}
+	parser.AddCommand("run-pipeline", "Run a pipeline with overrides", "Run a pipeline with values from a .env file passed as pipeline variables, without storing them", runPipelineCmd)
+
+	envsCmd := &EnvsCommand{global: global}
+	parser.AddCommand("envs", "List environments", "List GitLab environments and the number of variables applying to each", envsCmd)
+
+	validateCmd := &ValidateCommand{global: global}
+	parser.AddCommand("validate", "Validate .env files", "Check .env files against the variable schema without contacting GitLab", validateCmd)
+
+	lintCmd := &LintCommand{global: global}
+	parser.AddCommand("lint", "Lint .env files", "Report duplicate keys, invalid keys and other problems in .env files", lintCmd)
+
+	configCmd, _ := parser.AddCommand("config", "Configuration", "Inspect the resolved configuration", &ConfigCommand{})
+	configCmd.AddCommand("show", "Show resolved config", "Print the resolved configuration with the origin of each value", &ConfigShowCommand{global: global})
+
+	authCmd, _ := parser.AddCommand("auth", "Authentication", "Inspect GitLab authentication", &AuthCommand{})
+	authCmd.AddCommand("login", "Log in with OAuth", "Obtain an OAuth token through the device flow and save it for gitlab.auth: oauth", &AuthLoginCommand{global: global})
+	authCmd.AddCommand("status", "Show token status", "Show where the GitLab token comes from, its scopes and expiry", &AuthStatusCommand{global: global})
+
 	deleteCmd := &DeleteCommand{global: global}
 	parser.AddCommand("delete", "Delete variable(s)", "Delete one or more GitLab CI/CD variables", deleteCmd)
 
@@ -1,9 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ohmylock/glenv/pkg/config"
+	"github.com/ohmylock/glenv/pkg/gitlab"
+	"github.com/ohmylock/glenv/pkg/source"
 )
 
 func cfg(envs map[string]config.EnvironmentConfig) *config.Config {
@@ -85,6 +94,64 @@ func TestResolveEnvFile(t *testing.T) {
 	}
 }
 
+func TestResolveSource(t *testing.T) {
+	secrets := &config.SourceConfig{Type: "dir", Path: "/run/secrets"}
+	envs := map[string]config.EnvironmentConfig{
+		"production": {Source: secrets},
+		"staging":    {File: "staging.env"},
+	}
+	tests := []struct {
+		name        string
+		flagFile    string
+		environment string
+		want        string
+	}{
+		{"configured source", "", "production", "dir:/run/secrets"},
+		{"explicit flag wins over source", "local.env", "production", "local.env"},
+		{"environment without source uses its file", "", "staging", "staging.env"},
+		{"wildcard scope uses .env", "", "*", ".env"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := resolveSource(tt.flagFile, tt.environment, cfg(envs))
+			if err != nil {
+				t.Fatalf("resolveSource: %v", err)
+			}
+			if got := src.String(); got != tt.want {
+				t.Errorf("resolveSource(%q, %q) = %q, want %q", tt.flagFile, tt.environment, got, tt.want)
+			}
+		})
+	}
+
+	bad := cfg(map[string]config.EnvironmentConfig{"production": {Source: &config.SourceConfig{Type: "s3"}}})
+	if _, err := resolveSource("", "production", bad); err == nil {
+		t.Error("resolveSource: expected error for unknown source type")
+	}
+}
+
+func TestResolveSource_VaultUsesHTTPConfig(t *testing.T) {
+	vault := &config.SourceConfig{Type: "vault", Address: "https://vault.internal", Token: "root", Path: "app/prod"}
+	c := cfg(map[string]config.EnvironmentConfig{"production": {Source: vault}})
+	c.HTTP.Timeout = 7 * time.Second
+
+	src, err := resolveSource("", "production", c)
+	if err != nil {
+		t.Fatalf("resolveSource: %v", err)
+	}
+	v, ok := src.(*source.Vault)
+	if !ok {
+		t.Fatalf("resolveSource = %T, want *source.Vault", src)
+	}
+	if v.HTTP == nil || v.HTTP.Timeout != 7*time.Second {
+		t.Errorf("vault HTTP client does not use the http section: %+v", v.HTTP)
+	}
+
+	c.HTTP.CACert = filepath.Join(t.TempDir(), "missing.pem")
+	if _, err := resolveSource("", "production", c); err == nil {
+		t.Error("resolveSource: expected error for a missing CA bundle")
+	}
+}
+
 func TestResolveWorkers(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -205,3 +272,124 @@ func TestBuildTags(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchVariables(t *testing.T) {
+	vars := []gitlab.Variable{
+		{Key: "LEGACY_API", EnvironmentScope: "*"},
+		{Key: "LEGACY_DB", EnvironmentScope: "review/*"},
+		{Key: "LEGACY_DB", EnvironmentScope: "review/feature-x"},
+		{Key: "LEGACY_DB", EnvironmentScope: "production"},
+		{Key: "CURRENT_DB", EnvironmentScope: "review/*"},
+	}
+
+	keysAndScopes := func(vs []gitlab.Variable) []string {
+		out := make([]string, 0, len(vs))
+		for _, v := range vs {
+			out = append(out, v.Key+"@"+v.EnvironmentScope)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		scope    string
+		want     []string
+	}{
+		{
+			name:     "key pattern across all scopes",
+			patterns: []string{"LEGACY_*"},
+			want:     []string{"LEGACY_API@*", "LEGACY_DB@review/*", "LEGACY_DB@review/feature-x", "LEGACY_DB@production"},
+		},
+		{
+			name:     "scope pattern matches literal and concrete scopes",
+			patterns: []string{"LEGACY_*"},
+			scope:    "review/*",
+			want:     []string{"LEGACY_DB@review/*", "LEGACY_DB@review/feature-x"},
+		},
+		{
+			name:     "star scope selects the default scope only",
+			patterns: []string{"LEGACY_*"},
+			scope:    "*",
+			want:     []string{"LEGACY_API@*"},
+		},
+		{
+			name:     "multiple key patterns",
+			patterns: []string{"LEGACY_API", "CURRENT_*"},
+			want:     []string{"LEGACY_API@*", "CURRENT_DB@review/*"},
+		},
+		{
+			name:     "no match",
+			patterns: []string{"NOPE_*"},
+			want:     []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchVariables(vars, tt.patterns, tt.scope)
+			if err != nil {
+				t.Fatalf("matchVariables() error = %v", err)
+			}
+			if g := keysAndScopes(got); strings.Join(g, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matchVariables() = %v, want %v", g, tt.want)
+			}
+		})
+	}
+
+	if _, err := matchVariables(vars, []string{"["}, ""); err == nil {
+		t.Error("matchVariables() with invalid pattern: expected error")
+	}
+}
+
+func TestSplitCircuitErrors(t *testing.T) {
+	other := errors.New("update A: gitlab: status 400")
+	errs, skipped := splitCircuitErrors([]error{
+		fmt.Errorf("create B: %w", gitlab.ErrCircuitOpen),
+		other,
+		fmt.Errorf("create C: %w", gitlab.ErrCircuitOpen),
+	})
+	if len(errs) != 1 || errs[0] != other {
+		t.Errorf("errs = %v, want [%v]", errs, other)
+	}
+	if skipped != 2 {
+		t.Errorf("circuitOpen = %d, want 2", skipped)
+	}
+}
+
+func TestLoadSource_ResolvesLocalFilesOnly(t *testing.T) {
+	appCtx = context.Background()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "secrets"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secrets", "db.txt"), []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	envFile := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envFile, []byte("DB_PASSWORD=ref+file://secrets/db.txt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := loadSource(source.File{Path: envFile}, true)
+	if err != nil {
+		t.Fatalf("loadSource(file) error = %v", err)
+	}
+	if got := parsed.Variables[0].Value; got != "s3cret" {
+		t.Errorf("file reference = %q, want s3cret (relative to the .env file)", got)
+	}
+
+	remote := filepath.Join(dir, "remote")
+	if err := os.MkdirAll(remote, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(remote, "TOKEN"), []byte("ref+cmd://touch pwned"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err = loadSource(source.Dir{Path: remote}, true)
+	if err != nil {
+		t.Fatalf("loadSource(dir) error = %v", err)
+	}
+	if got := parsed.Variables[0].Value; got != "ref+cmd://touch pwned" {
+		t.Errorf("dir source value = %q, want the reference unresolved", got)
+	}
+}
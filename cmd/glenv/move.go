@@ -0,0 +1,130 @@
+//nolint:errcheck // CLI output errors are intentionally ignored
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ohmylock/glenv/pkg/classifier"
+	"github.com/ohmylock/glenv/pkg/gitlab"
+	glsync "github.com/ohmylock/glenv/pkg/sync"
+)
+
+// relocation is a single planned move of a variable to a new key and/or scope.
+type relocation struct {
+	src      gitlab.Variable
+	dstKey   string
+	dstScope string
+}
+
+// MoveCommand renames a variable in place, in every scope or in selected ones.
+type MoveCommand struct {
+	Scope  []string `long:"scope" description:"Only rename the variable in this environment scope (repeatable; default: all scopes)"`
+	Force  bool     `long:"force" description:"Skip confirmation prompt"`
+	global *GlobalOptions
+}
+
+func (cmd *MoveCommand) Execute(args []string) error {
+	setupColor(cmd.global.NoColor)
+	if len(args) != 2 {
+		return fmt.Errorf("usage: glenv mv OLD NEW")
+	}
+	oldKey, newKey := args[0], args[1]
+	if oldKey == newKey {
+		return fmt.Errorf("OLD and NEW must differ")
+	}
+
+	cfg, client, err := buildClientFromGlobal(cmd.global)
+	if err != nil {
+		return err
+	}
+	vars, err := client.ListVariables(appCtx, cfg.GitLab.ProjectID, gitlab.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list variables: %w", err)
+	}
+
+	var moves []relocation
+	for _, v := range vars {
+		if v.Key != oldKey || (len(cmd.Scope) > 0 && !slices.Contains(cmd.Scope, v.EnvironmentScope)) {
+			continue
+		}
+		moves = append(moves, relocation{src: v, dstKey: newKey, dstScope: v.EnvironmentScope})
+	}
+	if len(moves) == 0 {
+		return fmt.Errorf("variable %s not found in the selected scopes", oldKey)
+	}
+
+	return runRelocations(cmd.global, cfg.GitLab.ProjectID, client, moves, cmd.Force)
+}
+
+// RescopeCommand moves a variable from one environment scope to another.
+type RescopeCommand struct {
+	From   string `long:"from" description:"Current environment scope" required:"true"`
+	To     string `long:"to" description:"New environment scope" required:"true"`
+	Force  bool   `long:"force" description:"Skip confirmation prompt"`
+	global *GlobalOptions
+}
+
+func (cmd *RescopeCommand) Execute(args []string) error {
+	setupColor(cmd.global.NoColor)
+	if len(args) != 1 {
+		return fmt.Errorf("usage: glenv rescope KEY --from SCOPE --to SCOPE")
+	}
+	key := args[0]
+	if cmd.From == cmd.To {
+		return fmt.Errorf("--from and --to must differ")
+	}
+
+	cfg, client, err := buildClientFromGlobal(cmd.global)
+	if err != nil {
+		return err
+	}
+	vars, err := client.ListVariables(appCtx, cfg.GitLab.ProjectID, gitlab.ListOptions{EnvironmentScope: cmd.From})
+	if err != nil {
+		return fmt.Errorf("list variables: %w", err)
+	}
+
+	idx := slices.IndexFunc(vars, func(v gitlab.Variable) bool {
+		return v.Key == key && v.EnvironmentScope == cmd.From
+	})
+	if idx < 0 {
+		return fmt.Errorf("variable %s not found in scope %q", key, cmd.From)
+	}
+
+	moves := []relocation{{src: vars[idx], dstKey: key, dstScope: cmd.To}}
+	return runRelocations(cmd.global, cfg.GitLab.ProjectID, client, moves, cmd.Force)
+}
+
+// runRelocations prints the planned moves, asks for confirmation and performs
+// them one by one. A move never deletes the source unless its copy was created.
+func runRelocations(global *GlobalOptions, projectID string, client *gitlab.Client, moves []relocation, force bool) error {
+	for _, m := range moves {
+		fmt.Printf("~ %s (%s) → %s (%s)\n", m.src.Key, m.src.EnvironmentScope, m.dstKey, m.dstScope)
+	}
+	if !global.DryRun && !force {
+		if !confirm(fmt.Sprintf("Move %d variable(s)?", len(moves))) {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	engine := glsync.NewEngine(client, classifier.NewEmpty(), glsync.Options{DryRun: global.DryRun}, projectID)
+	var failed int
+	for _, m := range moves {
+		if err := engine.Relocate(appCtx, m.src, m.dstKey, m.dstScope); err != nil {
+			red.Printf("✗ %v\n", err)
+			failed++
+			continue
+		}
+		if global.DryRun {
+			cyan.Printf("✓ %s (%s) can be moved\n", m.src.Key, m.src.EnvironmentScope)
+			continue
+		}
+		green.Printf("✓ moved %s (%s) → %s (%s)\n", m.src.Key, m.src.EnvironmentScope, m.dstKey, m.dstScope)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d move(s) failed", failed)
+	}
+	return nil
+}
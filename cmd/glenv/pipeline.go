@@ -0,0 +1,193 @@
+//nolint:errcheck // CLI output errors are intentionally ignored
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ohmylock/glenv/pkg/classifier"
+	"github.com/ohmylock/glenv/pkg/config"
+	"github.com/ohmylock/glenv/pkg/envfile"
+	"github.com/ohmylock/glenv/pkg/gitlab"
+	glsync "github.com/ohmylock/glenv/pkg/sync"
+)
+
+// RunPipelineCommand starts a one-off pipeline with values from a local .env
+// file passed as pipeline variables; nothing is stored in the project.
+type RunPipelineCommand struct {
+	Ref            string `long:"ref" description:"Ref to run the pipeline on (default: pipeline.ref or the default branch)"`
+	File           string `short:"f" long:"file" description:"Path to .env file with pipeline variable overrides" required:"true"`
+	Wait           bool   `long:"wait" description:"Wait for the pipeline to finish"`
+	NoAutoClassify bool   `long:"no-auto-classify" description:"Disable automatic variable classification"`
+	Force          bool   `long:"force" description:"Skip confirmation prompt when secrets are detected"`
+	global         *GlobalOptions
+}
+
+func (cmd *RunPipelineCommand) Execute(args []string) error {
+	setupColor(cmd.global.NoColor)
+	cfg, client, err := buildClientFromGlobal(cmd.global)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := envfile.ParseFile(cmd.File)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", cmd.File, err)
+	}
+	vars, secrets := pipelineVariables(parsed.Variables, buildClassifier(cfg, cmd.NoAutoClassify))
+
+	secret := make(map[string]bool, len(secrets))
+	for _, k := range secrets {
+		secret[k] = true
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tTYPE\tVALUE")
+	for _, v := range vars {
+		val := displayValue(gitlab.Variable{Value: v.Value, VariableType: v.VariableType, Masked: secret[v.Key]}, false)
+		fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key, v.VariableType, val)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+	fmt.Printf("\nTotal: %d pipeline variables\n", len(vars))
+
+	if len(secrets) > 0 {
+		yellow.Printf("\nwarning: %d variable(s) look like secrets: %s\n", len(secrets), strings.Join(secrets, ", "))
+		yellow.Println("Pipeline variables are not masked in job logs and are visible to anyone who can view the pipeline.")
+	}
+	if cmd.global.DryRun {
+		return nil
+	}
+	if len(secrets) > 0 && !cmd.Force && !confirm("Run the pipeline anyway?") {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	fmt.Println()
+	return runPipeline(client, cfg.GitLab.ProjectID, newPipelineRun(cfg.Pipeline, cmd.Ref, cmd.Wait), vars)
+}
+
+// pipelineVariables converts parsed .env variables into pipeline variables,
+// keeping the file type from the classifier, and returns the keys that look
+// secret: those matching a secret key pattern, whether or not their value
+// could be masked, and file variables.
+func pipelineVariables(vars []envfile.Variable, cl *classifier.Classifier) ([]gitlab.PipelineVariable, []string) {
+	out := make([]gitlab.PipelineVariable, 0, len(vars))
+	var secrets []string
+	for _, v := range vars {
+		c := cl.Classify(v.Key, v.Value, "")
+		out = append(out, gitlab.PipelineVariable{Key: v.Key, Value: v.Value, VariableType: c.VarType})
+		if cl.IsSecret(v.Key) || c.VarType == "file" {
+			secrets = append(secrets, v.Key)
+		}
+	}
+	return out, secrets
+}
+
+// pipelineRun describes a pipeline to start and whether to wait for it.
+type pipelineRun struct {
+	ref          string // empty means the project's default branch
+	wait         bool
+	timeout      time.Duration
+	pollInterval time.Duration
+}
+
+// newPipelineRun merges pipeline settings from config with command-line overrides.
+func newPipelineRun(cfg config.PipelineConfig, ref string, wait bool) pipelineRun {
+	run := pipelineRun{
+		ref:          cfg.Ref,
+		wait:         cfg.Wait || wait,
+		timeout:      cfg.Timeout,
+		pollInterval: cfg.PollInterval,
+	}
+	if ref != "" {
+		run.ref = ref
+	}
+	return run
+}
+
+// runPipeline starts a pipeline with vars and, if requested, waits for it to
+// finish. See pipelineResult for how the final status is reported.
+func runPipeline(client *gitlab.Client, projectID string, run pipelineRun, vars []gitlab.PipelineVariable) error {
+	ref := run.ref
+	if ref == "" {
+		project, err := client.GetProject(appCtx, projectID)
+		if err != nil {
+			return fmt.Errorf("resolve default branch: %w", err)
+		}
+		if project.DefaultBranch == "" {
+			return errors.New("project has no default branch; set pipeline.ref or pass --ref")
+		}
+		ref = project.DefaultBranch
+	}
+
+	p, err := client.CreatePipeline(appCtx, projectID, ref, vars)
+	if err != nil {
+		return fmt.Errorf("trigger pipeline: %w", err)
+	}
+	green.Printf("✓ pipeline #%d started on %s\n", p.ID, ref)
+	if p.WebURL != "" {
+		fmt.Printf("  %s\n", p.WebURL)
+	}
+	if !run.wait {
+		return nil
+	}
+
+	ctx := appCtx
+	if run.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(appCtx, run.timeout)
+		defer cancel()
+	}
+	interval := run.pollInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	p, err = client.WaitPipeline(ctx, projectID, p.ID, interval, func(p *gitlab.Pipeline) {
+		gray.Printf("  pipeline #%d: %s\n", p.ID, p.Status)
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("pipeline did not finish within %s", run.timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("wait for pipeline: %w", err)
+	}
+	return pipelineResult(p)
+}
+
+// pipelineResult reports the final status of a waited-for pipeline. A pipeline
+// stopped at a manual job is not a failure: it waits for someone to start the
+// job, so that is reported without an error. Any other status but "success"
+// is an error.
+func pipelineResult(p *gitlab.Pipeline) error {
+	switch p.Status {
+	case "success":
+		green.Printf("✓ pipeline #%d succeeded\n", p.ID)
+		return nil
+	case "manual":
+		yellow.Printf("⏸ pipeline #%d is waiting for a manual job\n", p.ID)
+		return nil
+	}
+	red.Printf("✗ pipeline #%d finished with status %s\n", p.ID, p.Status)
+	return fmt.Errorf("pipeline #%d finished with status %s", p.ID, p.Status)
+}
+
+// syncPipelineVariables summarizes a sync for the triggered pipeline. Only key
+// names and counts are passed, never values.
+func syncPipelineVariables(envScope string, changed []string, report glsync.SyncReport) []gitlab.PipelineVariable {
+	keys := append([]string(nil), changed...)
+	sort.Strings(keys)
+	return []gitlab.PipelineVariable{
+		{Key: "GLENV_ENVIRONMENT", Value: envScope},
+		{Key: "GLENV_CHANGED_KEYS", Value: strings.Join(keys, ",")},
+		{Key: "GLENV_SYNC_SUMMARY", Value: fmt.Sprintf("created=%d updated=%d deleted=%d",
+			report.Created, report.Updated, report.Deleted)},
+	}
+}
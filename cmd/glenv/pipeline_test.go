@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ohmylock/glenv/pkg/classifier"
+	"github.com/ohmylock/glenv/pkg/config"
+	"github.com/ohmylock/glenv/pkg/envfile"
+	"github.com/ohmylock/glenv/pkg/gitlab"
+	glsync "github.com/ohmylock/glenv/pkg/sync"
+)
+
+func TestNewPipelineRun(t *testing.T) {
+	cfg := config.PipelineConfig{Ref: "main", Timeout: time.Minute, PollInterval: time.Second}
+
+	run := newPipelineRun(cfg, "", false)
+	if run.ref != "main" || run.wait || run.timeout != time.Minute {
+		t.Errorf("newPipelineRun(config only) = %+v", run)
+	}
+
+	run = newPipelineRun(cfg, "deploy", true)
+	if run.ref != "deploy" || !run.wait {
+		t.Errorf("newPipelineRun(flags) = %+v, want ref deploy and wait", run)
+	}
+}
+
+func TestSyncPipelineVariables(t *testing.T) {
+	report := glsync.SyncReport{Created: 1, Updated: 2}
+	vars := syncPipelineVariables("production", []string{"B", "C", "A"}, report)
+
+	want := map[string]string{
+		"GLENV_ENVIRONMENT":  "production",
+		"GLENV_CHANGED_KEYS": "A,B,C",
+		"GLENV_SYNC_SUMMARY": "created=1 updated=2 deleted=0",
+	}
+	if len(vars) != len(want) {
+		t.Fatalf("got %d variables, want %d", len(vars), len(want))
+	}
+	for _, v := range vars {
+		if v.Value != want[v.Key] {
+			t.Errorf("%s = %q, want %q", v.Key, v.Value, want[v.Key])
+		}
+	}
+}
+
+func TestPipelineVariables(t *testing.T) {
+	vars := []envfile.Variable{
+		{Key: "LOG_LEVEL", Value: "debug"},
+		{Key: "API_TOKEN", Value: "abcdefgh12345678"},
+		{Key: "TLS_CERT", Value: "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----"},
+		{Key: "DB_PASSWORD", Value: "pa ss"}, // cannot be masked, still secret
+	}
+
+	out, secrets := pipelineVariables(vars, classifier.New(classifier.Rules{}))
+	if len(out) != 4 {
+		t.Fatalf("got %d pipeline variables, want 4", len(out))
+	}
+	if out[0].VariableType != "env_var" || out[2].VariableType != "file" {
+		t.Errorf("variable types = %q, %q; want env_var, file", out[0].VariableType, out[2].VariableType)
+	}
+	if len(secrets) != 3 || secrets[0] != "API_TOKEN" || secrets[1] != "TLS_CERT" || secrets[2] != "DB_PASSWORD" {
+		t.Errorf("secrets = %v, want [API_TOKEN TLS_CERT DB_PASSWORD]", secrets)
+	}
+}
+
+func TestPipelineResult(t *testing.T) {
+	for status, wantErr := range map[string]bool{
+		"success":  false,
+		"manual":   false,
+		"failed":   true,
+		"canceled": true,
+		"skipped":  true,
+	} {
+		err := pipelineResult(&gitlab.Pipeline{ID: 7, Status: status})
+		if (err != nil) != wantErr {
+			t.Errorf("%s: err = %v, want error %t", status, err, wantErr)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+//nolint:errcheck // CLI output errors are intentionally ignored
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ohmylock/glenv/pkg/classifier"
+	"github.com/ohmylock/glenv/pkg/config"
+	"github.com/ohmylock/glenv/pkg/envfile"
+	"github.com/ohmylock/glenv/pkg/gitlab"
+	"github.com/ohmylock/glenv/pkg/hooks"
+	"github.com/ohmylock/glenv/pkg/source"
+	glsync "github.com/ohmylock/glenv/pkg/sync"
+)
+
+// PlanCommand computes a diff and saves it to a plan file for later apply.
+type PlanCommand struct {
+	File           string `short:"f" long:"file" description:"Path to .env file (resolves from config or defaults to .env)"`
+	Environment    string `short:"e" long:"environment" description:"GitLab environment scope" default:"*"`
+	DeleteMissing  bool   `long:"delete-missing" description:"Plan deletion of remote variables not present in .env file"`
+	NoAutoClassify bool   `long:"no-auto-classify" description:"Disable automatic variable classification"`
+	Output         string `short:"o" long:"output" description:"Path to write the plan file" required:"true"`
+	NoResolve      bool   `long:"no-resolve" description:"Plan ref+ value references as written instead of resolving them"`
+	global         *GlobalOptions
+}
+
+func (cmd *PlanCommand) Execute(args []string) error {
+	setupColor(cmd.global.NoColor)
+	cfg, client, err := buildClientFromGlobal(cmd.global)
+	if err != nil {
+		return err
+	}
+
+	parsed, envFile, err := loadLocal(cmd.File, cmd.Environment, cfg, !cmd.NoResolve)
+	if err != nil {
+		return err
+	}
+
+	s, err := loadSchema(cfg, cmd.global.Schema)
+	if err != nil {
+		return err
+	}
+	if err := checkSchema(s, parsed, cmd.Environment, envFile); err != nil {
+		return err
+	}
+
+	if _, err := checkScope(client, cfg.GitLab.ProjectID, cmd.Environment, false); err != nil {
+		return err
+	}
+
+	cl := buildClassifier(cfg, cmd.NoAutoClassify)
+	opts := glsync.Options{
+		Workers:       resolveWorkers(cmd.global, cfg),
+		DeleteMissing: cmd.DeleteMissing,
+	}
+	engine := glsync.NewEngine(client, cl, opts, cfg.GitLab.ProjectID)
+
+	remote, err := client.ListVariables(appCtx, cfg.GitLab.ProjectID, gitlab.ListOptions{EnvironmentScope: cmd.Environment})
+	if err != nil {
+		return fmt.Errorf("list remote variables: %w", err)
+	}
+
+	diff := engine.Diff(appCtx, parsed.Variables, remote, cmd.Environment)
+	printDiff(diff)
+	printDiffSummary(diff)
+
+	plan := glsync.NewPlan(diff, remote, cfg.GitLab.ProjectID, cmd.Environment, envFile)
+	f, err := os.OpenFile(cmd.Output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("create plan file: %w", err)
+	}
+	if err := glsync.WritePlan(f, plan); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close plan file: %w", err)
+	}
+
+	fmt.Printf("\nPlan saved to %s. Apply it with: glenv apply %s\n", cmd.Output, cmd.Output)
+	return nil
+}
+
+// ApplyCommand applies a plan file created by PlanCommand.
+type ApplyCommand struct {
+	File           string `short:"f" long:"file" description:"Path to .env file holding secret values (defaults to the file recorded in the plan)"`
+	Force          bool   `long:"force" description:"Skip confirmation prompt"`
+	ForceOverwrite bool   `long:"force-overwrite" description:"Overwrite variables even if they changed remotely since the plan"`
+	NoResolve      bool   `long:"no-resolve" description:"Read ref+ value references as written (use when the plan was made with --no-resolve)"`
+	global         *GlobalOptions
+}
+
+func (cmd *ApplyCommand) Execute(args []string) error {
+	setupColor(cmd.global.NoColor)
+	if len(args) != 1 {
+		return fmt.Errorf("usage: glenv apply PLAN_FILE")
+	}
+	printHeader()
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("open plan file: %w", err)
+	}
+	plan, err := glsync.ReadPlan(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	cfg, client, err := buildClientFromGlobal(cmd.global)
+	if err != nil {
+		return err
+	}
+	if plan.ProjectID != cfg.GitLab.ProjectID {
+		return fmt.Errorf("plan was created for project %s, not %s", plan.ProjectID, cfg.GitLab.ProjectID)
+	}
+
+	remote, err := client.ListVariables(appCtx, cfg.GitLab.ProjectID, gitlab.ListOptions{EnvironmentScope: plan.Environment})
+	if err != nil {
+		return fmt.Errorf("list remote variables: %w", err)
+	}
+	if plan.Fingerprint(remote) != plan.RemoteFingerprint {
+		return fmt.Errorf("remote variables in scope %q changed since the plan was created; run glenv plan again", plan.Environment)
+	}
+
+	var local []envfile.Variable
+	if plan.NeedsLocal() {
+		src, err := planSource(cmd.File, plan, cfg)
+		if err != nil {
+			return err
+		}
+		parsed, err := loadSource(src, !cmd.NoResolve)
+		if err != nil {
+			return err
+		}
+		local = parsed.Variables
+	}
+	diff, err := plan.DiffResult(local)
+	if err != nil {
+		return err
+	}
+
+	printDiff(diff)
+	if cmd.global.DryRun {
+		printDiffSummary(diff)
+		return nil
+	}
+	if !cmd.Force && !confirmDeletes(diff) {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	var runner hooks.Runner
+	payload := hooks.Payload{
+		ProjectID:   cfg.GitLab.ProjectID,
+		Environment: plan.Environment,
+		File:        plan.File,
+		Changes:     hooks.NewChanges(diff),
+	}
+	pre := payload
+	pre.Stage = hooks.PreApply
+	if err := runner.Run(appCtx, cfg.Hooks.PreApply, pre); err != nil {
+		return err
+	}
+
+	// Classification is already recorded in the plan.
+	opts := glsync.Options{
+		Workers:         resolveWorkers(cmd.global, cfg),
+		DetectConflicts: !cmd.ForceOverwrite,
+		Logger:          cmd.global.logger(),
+	}
+	engine := glsync.NewEngine(client, classifier.NewEmpty(), opts, cfg.GitLab.ProjectID)
+
+	fmt.Printf("\nApplying: %s → project %s (%s)\n", args[0], cfg.GitLab.ProjectID, plan.Environment)
+	fmt.Println(separator)
+	fmt.Println()
+	report := engine.ApplyWithCallback(appCtx, diff, func(r glsync.Result) {
+		printResult(r)
+	})
+
+	printSyncReport(report)
+
+	post := payload
+	post.Stage, post.Report = hooks.PostApply, hooks.NewReport(report)
+	hookErr := runner.Run(appCtx, cfg.Hooks.PostApply, post)
+	if report.Failed > 0 {
+		return errors.Join(fmt.Errorf("%d variable(s) failed to apply", report.Failed), hookErr)
+	}
+	return hookErr
+}
+
+// planSource returns where apply reads secret values from: the --file flag,
+// the environment's configured source if the plan was made from it, or the
+// file recorded in the plan.
+func planSource(flagFile string, plan *glsync.Plan, cfg *config.Config) (source.Source, error) {
+	if flagFile != "" {
+		return source.File{Path: flagFile}, nil
+	}
+	src, err := resolveSource("", plan.Environment, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if src.String() == plan.File {
+		return src, nil
+	}
+	return source.File{Path: plan.File}, nil
+}
@@ -0,0 +1,67 @@
+//nolint:errcheck // CLI output errors are intentionally ignored
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ohmylock/glenv/pkg/gitlab"
+)
+
+// maxDisplayValue is the number of characters of a value shown in tables.
+const maxDisplayValue = 60
+
+// ResolveCommand shows the value each job in an environment actually sees.
+type ResolveCommand struct {
+	Environment string `short:"e" long:"environment" description:"Environment name (e.g. review/feature-x)" required:"true"`
+	Reveal      bool   `long:"reveal" description:"Show values of masked variables"`
+	global      *GlobalOptions
+}
+
+func (cmd *ResolveCommand) Execute(args []string) error {
+	setupColor(cmd.global.NoColor)
+	cfg, client, err := buildClientFromGlobal(cmd.global)
+	if err != nil {
+		return err
+	}
+
+	vars, err := client.ListVariables(appCtx, cfg.GitLab.ProjectID, gitlab.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list variables: %w", err)
+	}
+
+	resolved := gitlab.ResolveScope(vars, cmd.Environment)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSCOPE")
+	for _, v := range resolved {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key, displayValue(v, cmd.Reveal), v.EnvironmentScope)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+	fmt.Printf("\nTotal: %d variables visible in %s\n", len(resolved), cmd.Environment)
+	return nil
+}
+
+// displayValue renders a variable value for a single table cell: masked values
+// are hidden unless reveal is set, file contents are summarized and long or
+// multi-line values are truncated.
+func displayValue(v gitlab.Variable, reveal bool) string {
+	switch {
+	case v.Masked && !reveal:
+		return "***"
+	case v.VariableType == "file":
+		return fmt.Sprintf("<file, %d bytes>", len(v.Value))
+	}
+	val := v.Value
+	if i := strings.IndexAny(val, "\r\n"); i >= 0 {
+		val = val[:i] + "…"
+	}
+	if r := []rune(val); len(r) > maxDisplayValue {
+		val = string(r[:maxDisplayValue]) + "…"
+	}
+	return val
+}
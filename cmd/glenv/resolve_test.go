@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ohmylock/glenv/pkg/gitlab"
+)
+
+func TestDisplayValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		v      gitlab.Variable
+		reveal bool
+		want   string
+	}{
+		{name: "plain value", v: gitlab.Variable{Value: "debug"}, want: "debug"},
+		{name: "masked hidden", v: gitlab.Variable{Value: "supersecret", Masked: true}, want: "***"},
+		{name: "masked revealed", v: gitlab.Variable{Value: "supersecret", Masked: true}, reveal: true, want: "supersecret"},
+		{name: "file summarized", v: gitlab.Variable{Value: "line1\nline2", VariableType: "file"}, want: "<file, 11 bytes>"},
+		{name: "multi-line truncated", v: gitlab.Variable{Value: "first\nsecond"}, want: "first…"},
+		{name: "long truncated", v: gitlab.Variable{Value: strings.Repeat("a", 70)}, want: strings.Repeat("a", maxDisplayValue) + "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := displayValue(tt.v, tt.reveal); got != tt.want {
+				t.Errorf("displayValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+//nolint:errcheck // CLI output errors are intentionally ignored
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/ohmylock/glenv/pkg/config"
+	"github.com/ohmylock/glenv/pkg/envfile"
+	"github.com/ohmylock/glenv/pkg/schema"
+)
+
+// ValidateCommand checks .env files against the variable schema without
+// contacting GitLab, e.g. from a pre-commit hook.
+type ValidateCommand struct {
+	File        string `short:"f" long:"file" description:"Path to .env file (resolves from config or defaults to .env)"`
+	Environment string `short:"e" long:"environment" description:"Environment to validate for" default:"*"`
+	All         bool   `short:"a" long:"all" description:"Validate all environments defined in config"`
+	NoResolve   bool   `long:"no-resolve" description:"Validate ref+ value references as written instead of resolving them"`
+	global      *GlobalOptions
+}
+
+func (cmd *ValidateCommand) Execute(args []string) error {
+	setupColor(cmd.global.NoColor)
+	cfg, err := config.LoadProfile(cmd.global.Config, cmd.global.Profile)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	s, err := loadSchema(cfg, cmd.global.Schema)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return fmt.Errorf("no schema found: add a schema section to the config, create %s or pass --schema", schema.DefaultFile)
+	}
+
+	envNames := []string{cmd.Environment}
+	if cmd.All {
+		if len(cfg.Environments) == 0 {
+			return fmt.Errorf("--all requires environments to be defined in config file")
+		}
+		envNames = envNames[:0]
+		for name := range cfg.Environments {
+			envNames = append(envNames, name)
+		}
+		sort.Strings(envNames)
+	}
+
+	var errs []error
+	for _, env := range envNames {
+		parsed, envFile, err := loadLocal(cmd.File, env, cfg, !cmd.NoResolve)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := checkSchema(s, parsed, env, envFile); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		green.Printf("✓ %s (%s): valid\n", envFile, env)
+	}
+	return errors.Join(errs...)
+}
+
+// loadSchema returns the schema to validate against: the --schema file, the
+// schema section of the config, or schema.DefaultFile in the working
+// directory, in that order. It returns nil if none exists.
+func loadSchema(cfg *config.Config, path string) (*schema.Schema, error) {
+	if path != "" {
+		return schema.Load(path)
+	}
+	if cfg.Schema != nil {
+		return cfg.Schema, nil
+	}
+	if _, err := os.Stat(schema.DefaultFile); err == nil {
+		return schema.Load(schema.DefaultFile)
+	}
+	return nil, nil
+}
+
+// checkSchema prints schema violations of parsed and returns an error if there
+// are any. A nil schema accepts everything.
+func checkSchema(s *schema.Schema, parsed *envfile.ParseResult, environment, envFile string) error {
+	if s == nil {
+		return nil
+	}
+	violations := s.Validate(parsed, environment)
+	if len(violations) == 0 {
+		return nil
+	}
+	red.Printf("✗ %s (%s): %d schema violation(s)\n", envFile, environment, len(violations))
+	for _, v := range violations {
+		red.Printf("  %s\n", v)
+	}
+	return fmt.Errorf("%s: %d schema violation(s)", envFile, len(violations))
+}
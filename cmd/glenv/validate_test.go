@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ohmylock/glenv/pkg/config"
+	"github.com/ohmylock/glenv/pkg/envfile"
+	"github.com/ohmylock/glenv/pkg/schema"
+)
+
+func TestLoadSchema(t *testing.T) {
+	inline := &schema.Schema{Variables: map[string]*schema.Rule{"INLINE": {}}}
+	path := filepath.Join(t.TempDir(), "schema.yml")
+	if err := os.WriteFile(path, []byte("variables:\n  FROM_FILE: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(t.TempDir())
+
+	s, err := loadSchema(&config.Config{Schema: inline}, path)
+	if err != nil || s.Variables["FROM_FILE"] == nil {
+		t.Errorf("loadSchema(path) = %v, %v; want the --schema file", s, err)
+	}
+	s, err = loadSchema(&config.Config{Schema: inline}, "")
+	if err != nil || s != inline {
+		t.Errorf("loadSchema(config) = %v, %v; want the inline schema", s, err)
+	}
+	s, err = loadSchema(&config.Config{}, "")
+	if err != nil || s != nil {
+		t.Errorf("loadSchema(none) = %v, %v; want nil", s, err)
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	parsed := &envfile.ParseResult{Variables: []envfile.Variable{{Key: "PORT", Value: "80a", Line: 1}}}
+	s := &schema.Schema{Variables: map[string]*schema.Rule{"PORT": {Type: schema.TypeInt}}}
+
+	if err := checkSchema(nil, parsed, "*", ".env"); err != nil {
+		t.Errorf("checkSchema(nil) = %v, want nil", err)
+	}
+	if err := checkSchema(s, parsed, "*", ".env"); err == nil {
+		t.Error("checkSchema: expected error for PORT=80a")
+	}
+}
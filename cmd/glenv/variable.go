@@ -0,0 +1,139 @@
+//nolint:errcheck // CLI output errors are intentionally ignored
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ohmylock/glenv/pkg/envfile"
+	"github.com/ohmylock/glenv/pkg/gitlab"
+	glsync "github.com/ohmylock/glenv/pkg/sync"
+)
+
+// GetCommand prints the value of a single remote variable.
+type GetCommand struct {
+	Environment string `short:"e" long:"environment" description:"GitLab environment scope" default:"*"`
+	NoNewline   bool   `long:"no-newline" description:"Do not print a trailing newline (for piping to a clipboard)"`
+	global      *GlobalOptions
+}
+
+func (cmd *GetCommand) Execute(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: glenv get KEY")
+	}
+
+	cfg, client, err := buildClientFromGlobal(cmd.global)
+	if err != nil {
+		return err
+	}
+
+	v, err := client.GetVariable(appCtx, cfg.GitLab.ProjectID, args[0], cmd.Environment)
+	if err != nil {
+		return err
+	}
+	// GitLab versions that ignore the scope filter return the key from any
+	// scope; printing that value would be wrong for the requested one.
+	if v.EnvironmentScope != cmd.Environment {
+		return fmt.Errorf("%s not found in scope %q", args[0], cmd.Environment)
+	}
+
+	// Only the value goes to stdout so the output can be piped as-is.
+	fmt.Print(v.Value)
+	if !cmd.NoNewline {
+		fmt.Println()
+	}
+	return nil
+}
+
+// SetCommand creates or updates a single remote variable without a .env file.
+type SetCommand struct {
+	Environment    string `short:"e" long:"environment" description:"GitLab environment scope" default:"*"`
+	Masked         bool   `long:"masked" description:"Mark the variable as masked"`
+	Protected      bool   `long:"protected" description:"Mark the variable as protected"`
+	File           string `long:"file" description:"Read the value from a file (e.g. @cert.pem) and store it as a file variable"`
+	NoAutoClassify bool   `long:"no-auto-classify" description:"Disable automatic variable classification"`
+	ForceOverwrite bool   `long:"force-overwrite" description:"Overwrite the variable even if it changed remotely since it was read"`
+	global         *GlobalOptions
+}
+
+func (cmd *SetCommand) Execute(args []string) error {
+	setupColor(cmd.global.NoColor)
+	if len(args) != 1 {
+		return fmt.Errorf("usage: glenv set KEY=VALUE | glenv set KEY --file @path")
+	}
+	key, value, varType, err := parseSetArg(args[0], cmd.File)
+	if err != nil {
+		return err
+	}
+
+	cfg, client, err := buildClientFromGlobal(cmd.global)
+	if err != nil {
+		return err
+	}
+
+	// Look up the exact scope only: set must never modify a variable that is
+	// merely inherited from another scope.
+	var remote []gitlab.Variable
+	current, err := client.GetVariable(appCtx, cfg.GitLab.ProjectID, key, cmd.Environment)
+	switch {
+	case errors.Is(err, gitlab.ErrNotFound):
+	case err != nil:
+		return err
+	case current.EnvironmentScope == cmd.Environment:
+		remote = append(remote, *current)
+	}
+
+	cl := buildClassifier(cfg, cmd.NoAutoClassify)
+	opts := glsync.Options{
+		Workers:         1,
+		DryRun:          cmd.global.DryRun,
+		DetectConflicts: !cmd.ForceOverwrite,
+		Logger:          cmd.global.logger(),
+	}
+	engine := glsync.NewEngine(client, cl, opts, cfg.GitLab.ProjectID)
+
+	diff := engine.Diff(appCtx, []envfile.Variable{{Key: key, Value: value}}, remote, cmd.Environment)
+	if err := diff.Promote(key, varType, cmd.Masked, cmd.Protected); err != nil {
+		return err
+	}
+
+	printDiff(diff)
+	if cmd.global.DryRun {
+		return nil
+	}
+
+	report := engine.ApplyWithCallback(appCtx, diff, func(r glsync.Result) {
+		printResult(r)
+	})
+	if report.Conflicts > 0 {
+		fmt.Println("The variable changed remotely; re-run to review, or pass --force-overwrite.")
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("failed to set %s", key)
+	}
+	return nil
+}
+
+// parseSetArg splits the set argument into key and value. With file set, arg
+// is the bare key and the value is read from file (a leading "@" is optional);
+// the variable type is then forced to "file".
+func parseSetArg(arg, file string) (key, value, varType string, err error) {
+	if file != "" {
+		if strings.Contains(arg, "=") {
+			return "", "", "", fmt.Errorf("--file expects a bare KEY, got %q", arg)
+		}
+		data, err := os.ReadFile(strings.TrimPrefix(file, "@"))
+		if err != nil {
+			return "", "", "", fmt.Errorf("read value file: %w", err)
+		}
+		return arg, string(data), "file", nil
+	}
+
+	key, value, ok := strings.Cut(arg, "=")
+	if !ok || key == "" {
+		return "", "", "", fmt.Errorf("expected KEY=VALUE, got %q", arg)
+	}
+	return key, value, "", nil
+}
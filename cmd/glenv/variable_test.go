@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSetArg(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	if err := os.WriteFile(certPath, []byte("-----BEGIN CERTIFICATE-----\nabc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		arg         string
+		file        string
+		wantKey     string
+		wantValue   string
+		wantVarType string
+		wantErr     bool
+	}{
+		{name: "key=value", arg: "FOO=bar", wantKey: "FOO", wantValue: "bar"},
+		{name: "value containing =", arg: "DSN=a=b", wantKey: "DSN", wantValue: "a=b"},
+		{name: "empty value", arg: "EMPTY=", wantKey: "EMPTY", wantValue: ""},
+		{name: "missing =", arg: "FOO", wantErr: true},
+		{name: "empty key", arg: "=bar", wantErr: true},
+		{name: "file with @ prefix", arg: "TLS_CERT", file: "@" + certPath, wantKey: "TLS_CERT", wantValue: "-----BEGIN CERTIFICATE-----\nabc\n", wantVarType: "file"},
+		{name: "file without @ prefix", arg: "TLS_CERT", file: certPath, wantKey: "TLS_CERT", wantValue: "-----BEGIN CERTIFICATE-----\nabc\n", wantVarType: "file"},
+		{name: "file with key=value", arg: "TLS_CERT=x", file: certPath, wantErr: true},
+		{name: "missing file", arg: "TLS_CERT", file: filepath.Join(dir, "missing.pem"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, value, varType, err := parseSetArg(tt.arg, tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSetArg(%q, %q) error = %v, wantErr %v", tt.arg, tt.file, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if key != tt.wantKey || value != tt.wantValue || varType != tt.wantVarType {
+				t.Errorf("parseSetArg(%q, %q) = (%q, %q, %q), want (%q, %q, %q)",
+					tt.arg, tt.file, key, value, varType, tt.wantKey, tt.wantValue, tt.wantVarType)
+			}
+		})
+	}
+}
@@ -96,6 +96,20 @@ func (c *Classifier) Classify(key, value, environment string) Classification {
 	return cl
 }
 
+// IsSecret reports whether key matches the secret (masked) key patterns,
+// whether or not its value could be masked. A NewEmpty classifier uses the
+// built-in patterns here: disabling classification must not make secrets
+// displayable.
+func (c *Classifier) IsSecret(key string) bool {
+	if len(c.maskedPatterns) == 0 {
+		return builtin.matchesMasked(key)
+	}
+	return c.matchesMasked(key)
+}
+
+// builtin classifies with the built-in rules only.
+var builtin = New(Rules{})
+
 // isMaskable checks if a value can be masked by GitLab.
 // GitLab requires: >=8 chars, single-line with no spaces, and only chars from
 // [a-zA-Z0-9_:@-.+~=/] (alphanumeric plus @, :, ., ~, _, -, +, =, /).
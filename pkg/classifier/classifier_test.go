@@ -390,3 +390,12 @@ func TestNewEmpty_PEMValueNotFileType(t *testing.T) {
 	assert.Equal(t, "env_var", got.VarType, "PEM detection must be disabled for empty classifier")
 	assert.False(t, got.Protected)
 }
+
+func TestIsSecret(t *testing.T) {
+	c := defaultClassifier()
+	assert.True(t, c.IsSecret("DB_PASSWORD"), "secret key, whatever the value")
+	assert.False(t, c.IsSecret("MAX_TOKENS"))
+	assert.False(t, c.IsSecret("APP_NAME"))
+	assert.True(t, NewEmpty().IsSecret("DB_PASSWORD"), "built-in patterns without classification")
+	assert.True(t, New(Rules{MaskedPatterns: []string{"_PASS"}}).IsSecret("SMTP_PASS"))
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/ohmylock/glenv/pkg/schema"
 	"gopkg.in/yaml.v3"
 )
 
@@ -15,19 +16,72 @@ type GitLabConfig struct {
 	URL       string `yaml:"url"`
 	Token     string `yaml:"token"`
 	ProjectID string `yaml:"project_id"`
+	// TokenCommand prints the token on stdout, e.g. "pass gitlab/token".
+	TokenCommand string `yaml:"token_command"`
+	// Auth selects how the token is sent (see the Auth* constants).
+	Auth string `yaml:"auth"`
+	// OAuthClientID is the application ID used by "auth login" and to
+	// refresh OAuth tokens.
+	OAuthClientID string `yaml:"oauth_client_id"`
+	// TokenSource records where Token came from (see the TokenSource* constants).
+	TokenSource string `yaml:"-"`
 }
 
+// Authentication modes for GitLabConfig.Auth.
+const (
+	AuthToken    = "token"     // personal/project/group access token (default)
+	AuthJobToken = "job_token" // CI_JOB_TOKEN of a pipeline job
+	AuthOAuth    = "oauth"     // OAuth2 access token, e.g. from "glenv auth login"
+)
+
 // RateLimitConfig holds rate limiting and retry settings.
 type RateLimitConfig struct {
 	RequestsPerSecond   float64       `yaml:"requests_per_second"`
 	MaxConcurrent       int           `yaml:"max_concurrent"`
 	RetryMax            int           `yaml:"retry_max"`
 	RetryInitialBackoff time.Duration `yaml:"retry_initial_backoff"`
+	// RetryBudget caps network error and 5xx retries across all requests of a
+	// run; 429 retries are not counted. 0 disables the cap.
+	RetryBudget int `yaml:"retry_budget"`
+	// BreakerThreshold is the number of consecutive 5xx or network errors
+	// after which requests fail fast for BreakerCooldown; 0 disables it.
+	BreakerThreshold int           `yaml:"breaker_threshold"`
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown"`
+}
+
+// HTTPConfig holds TLS, proxy and timeout settings for the GitLab connection.
+type HTTPConfig struct {
+	CACert     string `yaml:"ca_cert"`     // PEM bundle trusted in addition to the system roots
+	ClientCert string `yaml:"client_cert"` // PEM client certificate for mutual TLS
+	ClientKey  string `yaml:"client_key"`
+	// InsecureSkipVerify disables TLS certificate verification.
+	InsecureSkipVerify  bool          `yaml:"insecure_skip_verify"`
+	Proxy               string        `yaml:"proxy"` // default: HTTPS_PROXY/HTTP_PROXY/NO_PROXY
+	Timeout             time.Duration `yaml:"timeout"`
+	DialTimeout         time.Duration `yaml:"dial_timeout"`
+	TLSHandshakeTimeout time.Duration `yaml:"tls_handshake_timeout"`
+}
+
+// SourceConfig selects where an environment's variables are read from
+// instead of a .env file.
+type SourceConfig struct {
+	Type string `yaml:"type"` // "file", "dir", "command" or "vault"
+	// Path is the file or directory for "file" and "dir", and the secret
+	// path within the mount for "vault".
+	Path    string `yaml:"path"`
+	Command string `yaml:"command"`
+	// Vault KV v2 settings. Address and Token default to VAULT_ADDR and
+	// VAULT_TOKEN; Mount defaults to "secret".
+	Address   string `yaml:"address"`
+	Token     string `yaml:"token"`
+	Mount     string `yaml:"mount"`
+	Namespace string `yaml:"namespace"`
 }
 
 // EnvironmentConfig defines a named deployment environment.
 type EnvironmentConfig struct {
-	File string `yaml:"file"`
+	File   string        `yaml:"file"`
+	Source *SourceConfig `yaml:"source"`
 }
 
 // ClassifyConfig holds user-supplied classification rule overrides.
@@ -38,12 +92,59 @@ type ClassifyConfig struct {
 	FileExclude    []string `yaml:"file_exclude"`
 }
 
+// PipelineConfig controls the pipeline triggered after a successful sync.
+type PipelineConfig struct {
+	Trigger      bool          `yaml:"trigger"`
+	Ref          string        `yaml:"ref"` // defaults to the project's default branch
+	Wait         bool          `yaml:"wait"`
+	Timeout      time.Duration `yaml:"timeout"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+}
+
+// Commands is a list of shell commands. In YAML it may be written as a
+// single string or as a list.
+type Commands []string
+
+// UnmarshalYAML accepts both a scalar and a sequence.
+func (c *Commands) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*c = Commands{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*c = list
+	return nil
+}
+
+// HooksConfig holds local commands run around sync. Each command receives a
+// JSON payload on stdin; a non-zero exit from a pre_* hook aborts the sync.
+type HooksConfig struct {
+	PreDiff   Commands `yaml:"pre_diff"`
+	PreApply  Commands `yaml:"pre_apply"`
+	PostApply Commands `yaml:"post_apply"`
+}
+
 // Config is the root configuration structure.
 type Config struct {
 	GitLab       GitLabConfig                 `yaml:"gitlab"`
 	RateLimit    RateLimitConfig              `yaml:"rate_limit"`
+	HTTP         HTTPConfig                   `yaml:"http"`
 	Environments map[string]EnvironmentConfig `yaml:"environments"`
 	Classify     ClassifyConfig               `yaml:"classify"`
+	Pipeline     PipelineConfig               `yaml:"pipeline"`
+	Hooks        HooksConfig                  `yaml:"hooks"`
+	// Schema is an inline variable schema; nil when the section is absent.
+	Schema *schema.Schema `yaml:"schema"`
+	// Profile is the profile used when neither --profile nor GLENV_PROFILE is set.
+	Profile  string                   `yaml:"profile"`
+	Profiles map[string]ProfileConfig `yaml:"profiles"`
+	// ActiveProfile is the name of the applied profile, if any.
+	ActiveProfile string `yaml:"-"`
+
+	origins map[string]string
 }
 
 // defaults returns a Config populated with built-in default values.
@@ -57,6 +158,16 @@ func defaults() Config {
 			MaxConcurrent:       5,
 			RetryMax:            3,
 			RetryInitialBackoff: time.Second,
+			RetryBudget:         50,
+			BreakerThreshold:    5,
+			BreakerCooldown:     30 * time.Second,
+		},
+		HTTP: HTTPConfig{
+			Timeout: 30 * time.Second,
+		},
+		Pipeline: PipelineConfig{
+			Timeout:      30 * time.Minute,
+			PollInterval: 10 * time.Second,
 		},
 	}
 }
@@ -66,12 +177,15 @@ func defaults() Config {
 func applyEnvVars(cfg *Config) {
 	if v := os.Getenv("GITLAB_TOKEN"); v != "" {
 		cfg.GitLab.Token = v
+		cfg.GitLab.TokenSource = TokenSourceEnv
 	}
 	if v := os.Getenv("GITLAB_PROJECT_ID"); v != "" {
 		cfg.GitLab.ProjectID = v
+		cfg.SetOrigin("gitlab.project_id", "GITLAB_PROJECT_ID")
 	}
 	if v := os.Getenv("GITLAB_URL"); v != "" {
 		cfg.GitLab.URL = v
+		cfg.SetOrigin("gitlab.url", "GITLAB_URL")
 	}
 }
 
@@ -80,12 +194,33 @@ func expandEnvVars(cfg *Config) {
 	cfg.GitLab.URL = os.ExpandEnv(cfg.GitLab.URL)
 	cfg.GitLab.Token = os.ExpandEnv(cfg.GitLab.Token)
 	cfg.GitLab.ProjectID = os.ExpandEnv(cfg.GitLab.ProjectID)
+	cfg.Pipeline.Ref = os.ExpandEnv(cfg.Pipeline.Ref)
+	expandHTTP(&cfg.HTTP)
+	for name, p := range cfg.Profiles {
+		p.GitLab.URL = os.ExpandEnv(p.GitLab.URL)
+		p.GitLab.Token = os.ExpandEnv(p.GitLab.Token)
+		p.GitLab.ProjectID = os.ExpandEnv(p.GitLab.ProjectID)
+		expandHTTP(&p.HTTP)
+		cfg.Profiles[name] = p
+	}
 	for name, envCfg := range cfg.Environments {
 		envCfg.File = os.ExpandEnv(envCfg.File)
+		if src := envCfg.Source; src != nil {
+			src.Path = os.ExpandEnv(src.Path)
+			src.Address = os.ExpandEnv(src.Address)
+			src.Token = os.ExpandEnv(src.Token)
+		}
 		cfg.Environments[name] = envCfg
 	}
 }
 
+func expandHTTP(h *HTTPConfig) {
+	h.CACert = os.ExpandEnv(h.CACert)
+	h.ClientCert = os.ExpandEnv(h.ClientCert)
+	h.ClientKey = os.ExpandEnv(h.ClientKey)
+	h.Proxy = os.ExpandEnv(h.Proxy)
+}
+
 // resolveConfigPath determines the config file path to use.
 //
 // Priority:
@@ -128,13 +263,22 @@ func resolveConfigPath(override, searchDir string) (string, error) {
 }
 
 // Load builds a Config using the priority chain:
-// defaults → YAML file → env var expansion → env vars (GITLAB_* override).
+// defaults → YAML file → env var expansion → profile → env vars (GITLAB_* override).
 //
 // configPath is the explicit config file path (e.g. from --config flag).
 // If empty, the automatic search chain is used.
 func Load(configPath string) (*Config, error) {
+	return LoadProfile(configPath, "")
+}
+
+// LoadProfile is Load with an explicitly selected profile (e.g. from the
+// --profile flag). An empty profile falls back to GLENV_PROFILE and then to
+// the profile key of the config file. Profiles are read from the config file
+// and from ~/.glenv.yml.
+func LoadProfile(configPath, profile string) (*Config, error) {
 	// Start with defaults.
 	cfg := defaults()
+	before := cfg.snapshot()
 
 	// Resolve config file.
 	resolved, err := resolveConfigPath(configPath, "")
@@ -152,9 +296,28 @@ func Load(configPath string) (*Config, error) {
 			return nil, fmt.Errorf("config: parse %q: %w", resolved, err)
 		}
 	}
+	if err := mergeHomeProfiles(&cfg, resolved); err != nil {
+		return nil, err
+	}
 
 	// Expand ${VAR} references in YAML string fields first.
 	expandEnvVars(&cfg)
+	if cfg.GitLab.Token != "" {
+		cfg.GitLab.TokenSource = TokenSourceConfig
+	}
+	if resolved != "" {
+		cfg.recordOrigin(before, fileOrigin(resolved))
+		cfg.SetOrigin("environments", fileOrigin(resolved))
+	}
+
+	if name, origin := selectProfile(profile, &cfg, resolved); name != "" {
+		before = cfg.snapshot()
+		if err := cfg.applyProfile(name); err != nil {
+			return nil, err
+		}
+		cfg.recordOrigin(before, "profile "+name)
+		cfg.SetOrigin("profile", origin)
+	}
 
 	// Overlay env vars last so they take precedence and are not re-expanded.
 	applyEnvVars(&cfg)
@@ -165,7 +328,12 @@ func Load(configPath string) (*Config, error) {
 // Validate checks that required fields are set.
 func (c *Config) Validate() error {
 	if c.GitLab.Token == "" {
-		return errors.New("config: gitlab.token is required (set GITLAB_TOKEN or token in config file)")
+		return errors.New("config: gitlab.token is required (set GITLAB_TOKEN, token or token_command in config file, or store it in a git credential helper, ~/.netrc or glab)")
+	}
+	switch c.GitLab.Auth {
+	case "", AuthToken, AuthJobToken, AuthOAuth:
+	default:
+		return fmt.Errorf("config: gitlab.auth: unknown mode %q (want %s, %s or %s)", c.GitLab.Auth, AuthToken, AuthJobToken, AuthOAuth)
 	}
 	if c.GitLab.ProjectID == "" {
 		return errors.New("config: gitlab.project_id is required (set GITLAB_PROJECT_ID or project_id in config file)")
@@ -25,6 +25,7 @@ func TestLoad_Defaults(t *testing.T) {
 	assert.Equal(t, 5, cfg.RateLimit.MaxConcurrent)
 	assert.Equal(t, 3, cfg.RateLimit.RetryMax)
 	assert.Equal(t, time.Second, cfg.RateLimit.RetryInitialBackoff)
+	assert.Equal(t, 50, cfg.RateLimit.RetryBudget)
 }
 
 func TestLoad_EnvVars(t *testing.T) {
@@ -182,6 +183,79 @@ classify:
 	assert.Contains(t, cfg.Classify.FileExclude, "CUSTOM_PATH")
 }
 
+func TestLoad_ConfigFile_Pipeline(t *testing.T) {
+	clearGitLabEnv(t)
+
+	yaml := `
+gitlab:
+  token: tok
+  project_id: "1"
+pipeline:
+  trigger: true
+  ref: deploy
+  wait: true
+  timeout: 5m
+`
+	path := writeTempConfig(t, yaml)
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	assert.True(t, cfg.Pipeline.Trigger)
+	assert.Equal(t, "deploy", cfg.Pipeline.Ref)
+	assert.True(t, cfg.Pipeline.Wait)
+	assert.Equal(t, 5*time.Minute, cfg.Pipeline.Timeout)
+	assert.Equal(t, 10*time.Second, cfg.Pipeline.PollInterval, "unset fields keep defaults")
+}
+
+func TestLoad_ConfigFile_Hooks(t *testing.T) {
+	clearGitLabEnv(t)
+
+	yaml := `
+gitlab:
+  token: tok
+  project_id: "1"
+hooks:
+  pre_diff: ./scripts/check-dsn.sh
+  post_apply:
+    - ./scripts/notify.sh
+    - echo done
+`
+	path := writeTempConfig(t, yaml)
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, Commands{"./scripts/check-dsn.sh"}, cfg.Hooks.PreDiff)
+	assert.Empty(t, cfg.Hooks.PreApply)
+	assert.Equal(t, Commands{"./scripts/notify.sh", "echo done"}, cfg.Hooks.PostApply)
+}
+
+func TestLoad_ConfigFile_Schema(t *testing.T) {
+	clearGitLabEnv(t)
+
+	path := writeTempConfig(t, `
+schema:
+  variables:
+    PORT:
+      type: int
+      required: true
+`)
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Schema)
+	assert.Equal(t, "int", cfg.Schema.Variables["PORT"].Type)
+
+	path = writeTempConfig(t, `
+schema:
+  variables:
+    PORT:
+      type: integer
+`)
+	_, err = Load(path)
+	assert.Error(t, err, "invalid schema is reported at load time")
+}
+
 func TestLoad_EnvVars_OverrideConfigFile(t *testing.T) {
 	// Env vars have higher priority than YAML config (Load order: defaults → YAML → env vars).
 	clearGitLabEnv(t)
@@ -274,7 +348,7 @@ func TestResolveConfigPath_NoFileFound(t *testing.T) {
 
 func clearGitLabEnv(t *testing.T) {
 	t.Helper()
-	for _, key := range []string{"GITLAB_TOKEN", "GITLAB_PROJECT_ID", "GITLAB_URL"} {
+	for _, key := range []string{"GITLAB_TOKEN", "GITLAB_PROJECT_ID", "GITLAB_URL", "GLENV_PROFILE"} {
 		t.Setenv(key, "") // set to empty so applyEnvVars skips it; t.Setenv restores original on cleanup
 	}
 }
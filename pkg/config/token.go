@@ -0,0 +1,272 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Token sources recorded in GitLabConfig.TokenSource.
+const (
+	TokenSourceFlag          = "--token flag"
+	TokenSourceEnv           = "GITLAB_TOKEN"
+	TokenSourceConfig        = "config file"
+	TokenSourceCommand       = "token_command"
+	TokenSourceGitCredential = "git credential helper"
+	TokenSourceNetrc         = "netrc"
+	TokenSourceGlab          = "glab config"
+	TokenSourceJobToken      = "CI_JOB_TOKEN"
+)
+
+// TokenProvider looks up a GitLab token for a host. Token returns "" and no
+// error when the provider has no token for the host.
+type TokenProvider interface {
+	Name() string
+	Token(ctx context.Context, host string) (string, error)
+}
+
+// TokenProviders returns the providers consulted when no token is set
+// explicitly, in order: token_command, git credential helper, netrc, glab.
+func (c *Config) TokenProviders() []TokenProvider {
+	var providers []TokenProvider
+	if c.GitLab.TokenCommand != "" {
+		providers = append(providers, CommandToken{Command: c.GitLab.TokenCommand})
+	}
+	return append(providers, GitCredentialToken{}, NetrcToken{}, GlabToken{})
+}
+
+// ResolveToken fills in GitLab.Token from the provider chain if no token was
+// set by flag, environment or config file, and records the source used.
+// Providers run lazily so commands that never contact GitLab don't invoke
+// credential helpers. With auth job_token the token defaults to CI_JOB_TOKEN;
+// with auth oauth the chain is skipped since it holds access tokens only.
+func (c *Config) ResolveToken(ctx context.Context) error {
+	if c.GitLab.Token != "" {
+		return nil
+	}
+	switch c.GitLab.Auth {
+	case AuthJobToken:
+		if v := os.Getenv("CI_JOB_TOKEN"); v != "" {
+			c.GitLab.Token = v
+			c.GitLab.TokenSource = TokenSourceJobToken
+		}
+		return nil
+	case AuthOAuth:
+		return nil
+	}
+	host := tokenHost(c.GitLab.URL)
+	for _, p := range c.TokenProviders() {
+		token, err := p.Token(ctx, host)
+		if err != nil {
+			return fmt.Errorf("config: token: %s: %w", p.Name(), err)
+		}
+		if token != "" {
+			c.GitLab.Token = token
+			c.GitLab.TokenSource = p.Name()
+			return nil
+		}
+	}
+	return nil
+}
+
+// tokenHost returns the host[:port] of the GitLab URL.
+func tokenHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Host
+}
+
+// CommandToken runs a shell command, e.g. "pass gitlab/token", and uses the
+// first line of its output as the token.
+type CommandToken struct {
+	Command string
+}
+
+// Name returns the token source name.
+func (CommandToken) Name() string { return TokenSourceCommand }
+
+// Token runs the command. A failing command is an error since it was
+// configured explicitly.
+func (p CommandToken) Token(ctx context.Context, _ string) (string, error) {
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", p.Command) //nolint:gosec // G204: command comes from user config, expected behavior
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%q: %w", p.Command, err)
+	}
+	line, _, _ := strings.Cut(stdout.String(), "\n")
+	token := strings.TrimSpace(line)
+	if token == "" {
+		return "", fmt.Errorf("%q printed no token", p.Command)
+	}
+	return token, nil
+}
+
+// GitCredentialToken asks git's configured credential helpers for the
+// password stored for https://host, without prompting.
+type GitCredentialToken struct{}
+
+// Name returns the token source name.
+func (GitCredentialToken) Name() string { return TokenSourceGitCredential }
+
+// Token runs "git credential fill". A missing git binary or helper means no
+// token rather than an error.
+func (GitCredentialToken) Token(ctx context.Context, host string) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", nil
+	}
+	cmd := exec.CommandContext(ctx, "git", "credential", "fill")
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=" + host + "\n\n")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", nil
+	}
+	return parseCredential(out), nil
+}
+
+// parseCredential extracts the password from git credential output.
+func parseCredential(out []byte) string {
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		if v, ok := strings.CutPrefix(s.Text(), "password="); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// NetrcToken reads the password of the host's entry in a netrc file.
+type NetrcToken struct {
+	Path string // defaults to $NETRC or ~/.netrc
+}
+
+// Name returns the token source name.
+func (NetrcToken) Name() string { return TokenSourceNetrc }
+
+// Token looks up host in the netrc file; a missing file means no token.
+func (p NetrcToken) Token(_ context.Context, host string) (string, error) {
+	path := p.Path
+	if path == "" {
+		path = os.Getenv("NETRC")
+	}
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", nil
+		}
+		path = filepath.Join(home, ".netrc")
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is the user's netrc file, expected behavior
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return parseNetrc(string(data), host), nil
+}
+
+// parseNetrc returns the password for machine host. Hostnames match without a
+// port. The default entry is ignored: its password is meant for anonymous
+// logins elsewhere and must not be sent to GitLab as a token.
+func parseNetrc(content, host string) string {
+	if h, _, ok := strings.Cut(host, ":"); ok {
+		host = h
+	}
+	var (
+		fields   = strings.Fields(content)
+		current  string // machine of the entry being read; empty for default
+		password string
+	)
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			if i+1 < len(fields) {
+				i++
+				current = fields[i]
+			}
+		case "default":
+			current = ""
+		case "password":
+			if i+1 >= len(fields) {
+				continue
+			}
+			i++
+			if current == host && password == "" {
+				password = fields[i]
+			}
+		case "login", "account":
+			i++
+		case "macdef":
+			// Macros are not supported; entries after one are ignored.
+			return password
+		}
+	}
+	return password
+}
+
+// GlabToken reads the token the glab CLI stored for the host.
+type GlabToken struct {
+	Path string // defaults to $GLAB_CONFIG_DIR/config.yml or $XDG_CONFIG_HOME/glab-cli/config.yml
+}
+
+// Name returns the token source name.
+func (GlabToken) Name() string { return TokenSourceGlab }
+
+// Token looks up host in the glab config; a missing file means no token.
+func (p GlabToken) Token(_ context.Context, host string) (string, error) {
+	path := p.Path
+	if path == "" {
+		dir := os.Getenv("GLAB_CONFIG_DIR")
+		if dir == "" {
+			base := os.Getenv("XDG_CONFIG_HOME")
+			if base == "" {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return "", nil
+				}
+				base = filepath.Join(home, ".config")
+			}
+			dir = filepath.Join(base, "glab-cli")
+		}
+		path = filepath.Join(dir, "config.yml")
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is the glab config file, expected behavior
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var cfg struct {
+		Hosts map[string]struct {
+			Token string `yaml:"token"`
+		} `yaml:"hosts"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return "", fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg.Hosts[host].Token, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
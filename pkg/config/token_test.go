@@ -0,0 +1,129 @@
+//nolint:errcheck // test file
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// isolateTokenProviders points every provider at an empty home directory so
+// tests never pick up the developer's real credentials.
+func isolateTokenProviders(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("NETRC", "")
+	t.Setenv("GLAB_CONFIG_DIR", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(home, ".gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	return home
+}
+
+func TestResolveToken_ExplicitTokenWins(t *testing.T) {
+	isolateTokenProviders(t)
+	cfg := &Config{GitLab: GitLabConfig{Token: "explicit", TokenSource: TokenSourceConfig, TokenCommand: "echo other"}}
+
+	require.NoError(t, cfg.ResolveToken(context.Background()))
+	assert.Equal(t, "explicit", cfg.GitLab.Token)
+	assert.Equal(t, TokenSourceConfig, cfg.GitLab.TokenSource)
+}
+
+func TestResolveToken_Command(t *testing.T) {
+	isolateTokenProviders(t)
+	cfg := &Config{GitLab: GitLabConfig{URL: "https://gitlab.com", TokenCommand: "printf 'glpat-cmd\\nsecond line\\n'"}}
+
+	require.NoError(t, cfg.ResolveToken(context.Background()))
+	assert.Equal(t, "glpat-cmd", cfg.GitLab.Token)
+	assert.Equal(t, TokenSourceCommand, cfg.GitLab.TokenSource)
+}
+
+func TestResolveToken_CommandFails(t *testing.T) {
+	isolateTokenProviders(t)
+	cfg := &Config{GitLab: GitLabConfig{URL: "https://gitlab.com", TokenCommand: "exit 3"}}
+
+	err := cfg.ResolveToken(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "token_command")
+}
+
+func TestResolveToken_Netrc(t *testing.T) {
+	home := isolateTokenProviders(t)
+	netrc := "machine github.com login x password gh\nmachine gitlab.example.com login oauth2 password glpat-netrc\n"
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".netrc"), []byte(netrc), 0o600))
+	cfg := &Config{GitLab: GitLabConfig{URL: "https://gitlab.example.com:8443"}}
+
+	require.NoError(t, cfg.ResolveToken(context.Background()))
+	assert.Equal(t, "glpat-netrc", cfg.GitLab.Token)
+	assert.Equal(t, TokenSourceNetrc, cfg.GitLab.TokenSource)
+}
+
+func TestResolveToken_Glab(t *testing.T) {
+	home := isolateTokenProviders(t)
+	dir := filepath.Join(home, ".config", "glab-cli")
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+	glab := "hosts:\n  gitlab.com:\n    token: glpat-glab\n    api_host: gitlab.com\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yml"), []byte(glab), 0o600))
+	cfg := &Config{GitLab: GitLabConfig{URL: "https://gitlab.com"}}
+
+	require.NoError(t, cfg.ResolveToken(context.Background()))
+	assert.Equal(t, "glpat-glab", cfg.GitLab.Token)
+	assert.Equal(t, TokenSourceGlab, cfg.GitLab.TokenSource)
+}
+
+func TestResolveToken_NoneFound(t *testing.T) {
+	isolateTokenProviders(t)
+	cfg := &Config{GitLab: GitLabConfig{URL: "https://gitlab.com"}}
+
+	require.NoError(t, cfg.ResolveToken(context.Background()))
+	assert.Empty(t, cfg.GitLab.Token)
+	assert.Empty(t, cfg.GitLab.TokenSource)
+}
+
+func TestParseNetrc(t *testing.T) {
+	content := `
+machine gitlab.com
+  login me
+  password first
+default login anon password fallback
+`
+	assert.Equal(t, "first", parseNetrc(content, "gitlab.com"))
+	assert.Equal(t, "first", parseNetrc(content, "gitlab.com:443"))
+	assert.Equal(t, "", parseNetrc(content, "other.example.com"), "the default entry is never used")
+	assert.Equal(t, "", parseNetrc("machine a password b", "c"))
+}
+
+func TestParseCredential(t *testing.T) {
+	out := []byte("protocol=https\nhost=gitlab.com\nusername=oauth2\npassword=glpat-git\n")
+	assert.Equal(t, "glpat-git", parseCredential(out))
+	assert.Equal(t, "", parseCredential([]byte("protocol=https\n")))
+}
+
+func TestLoad_TokenSource(t *testing.T) {
+	clearGitLabEnv(t)
+	t.Setenv("GITLAB_TOKEN", "from-env")
+
+	cfg, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, TokenSourceEnv, cfg.GitLab.TokenSource)
+}
+
+func TestResolveToken_JobToken(t *testing.T) {
+	isolateTokenProviders(t)
+	t.Setenv("CI_JOB_TOKEN", "job-token")
+	cfg := &Config{GitLab: GitLabConfig{URL: "https://gitlab.com", Auth: AuthJobToken}}
+
+	require.NoError(t, cfg.ResolveToken(context.Background()))
+	assert.Equal(t, "job-token", cfg.GitLab.Token)
+	assert.Equal(t, TokenSourceJobToken, cfg.GitLab.TokenSource)
+}
+
+func TestValidate_UnknownAuth(t *testing.T) {
+	cfg := &Config{GitLab: GitLabConfig{Token: "t", ProjectID: "1", Auth: "basic"}}
+	assert.ErrorContains(t, cfg.Validate(), `unknown mode "basic"`)
+}
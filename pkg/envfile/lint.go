@@ -0,0 +1,212 @@
+package envfile
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// GitLab limits for CI/CD variables.
+const (
+	MaxKeyLength   = 255
+	MaxValueLength = 10000
+)
+
+// Lint rule names.
+const (
+	RuleCRLF               = "crlf"
+	RuleMissingEquals      = "missing-equals"
+	RuleInvalidKey         = "invalid-key"
+	RuleDuplicateKey       = "duplicate-key"
+	RuleTrailingWhitespace = "trailing-whitespace"
+	RuleValueTooLarge      = "value-too-large"
+	RuleUnterminatedQuote  = "unterminated-quote"
+)
+
+var validKey = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// ValidKey reports whether key is a valid GitLab variable name: letters,
+// digits and '_', at most MaxKeyLength characters.
+func ValidKey(key string) bool {
+	return len(key) <= MaxKeyLength && validKey.MatchString(key)
+}
+
+// LintIssue is a problem found in a .env file.
+type LintIssue struct {
+	Line    int
+	Rule    string
+	Message string
+	// Fixable is true when Fix resolves the issue.
+	Fixable bool
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%d: %s: %s", i.Line, i.Rule, i.Message)
+}
+
+// Lint reports problems that ParseReader tolerates silently: CRLF line
+// endings, lines without "=", keys GitLab rejects, duplicate keys, trailing
+// whitespace after unquoted values, values over GitLab's size limit and
+// unterminated quotes.
+func Lint(r io.Reader) ([]LintIssue, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("envfile: lint: %w", err)
+	}
+	issues, _ := lint(string(data))
+	return issues, nil
+}
+
+// Fix rewrites a .env file resolving all fixable issues: line endings become
+// LF, trailing whitespace is trimmed, earlier definitions of duplicate keys are
+// removed (matching ParseReader, where the last one wins) and lines without
+// "=" are commented out. It returns the fixed content and the issues that
+// remain.
+func Fix(r io.Reader) ([]byte, []LintIssue, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("envfile: fix: %w", err)
+	}
+	_, fixed := lint(string(data))
+	remaining, _ := lint(fixed)
+	return []byte(fixed), remaining, nil
+}
+
+// lint checks content and returns the issues together with the fixed content.
+func lint(content string) ([]LintIssue, string) {
+	var issues []LintIssue
+	trailingNewline := strings.HasSuffix(content, "\n")
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	crlf := 0
+	for i, l := range lines {
+		if strings.HasSuffix(l, "\r") {
+			crlf++
+			lines[i] = strings.TrimSuffix(l, "\r")
+		}
+	}
+	if crlf > 0 {
+		issues = append(issues, LintIssue{Line: 1, Rule: RuleCRLF, Fixable: true,
+			Message: fmt.Sprintf("file uses CRLF line endings (%d lines)", crlf)})
+	}
+
+	// definitions records the line range [start, end] of each key definition,
+	// so that fixing a duplicate can drop every line of a multi-line value.
+	type span struct{ start, end int }
+	definitions := make(map[string][]span)
+	drop := make(map[int]bool)
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "export ") {
+			trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "export "))
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		eqIdx := strings.Index(trimmed, "=")
+		if eqIdx < 0 {
+			issues = append(issues, LintIssue{Line: i + 1, Rule: RuleMissingEquals, Fixable: true,
+				Message: "line has no '=' and is ignored"})
+			lines[i] = "# " + line
+			continue
+		}
+
+		key := strings.TrimRight(trimmed[:eqIdx], " \t")
+		switch {
+		case key == "":
+			issues = append(issues, LintIssue{Line: i + 1, Rule: RuleInvalidKey, Message: "empty key"})
+		case len(key) > MaxKeyLength:
+			issues = append(issues, LintIssue{Line: i + 1, Rule: RuleInvalidKey,
+				Message: fmt.Sprintf("key is %d characters long (GitLab allows %d)", len(key), MaxKeyLength)})
+		case !validKey.MatchString(key):
+			issues = append(issues, LintIssue{Line: i + 1, Rule: RuleInvalidKey,
+				Message: fmt.Sprintf("key %q may only contain letters, digits and '_'", key)})
+		}
+
+		start := i
+		rawValue := trimmed[eqIdx+1:]
+		var value string
+		switch {
+		case rawValue != "" && rawValue[0] == '"':
+			inner := rawValue[1:]
+			if idx := findUnescapedQuote(inner); idx >= 0 {
+				value = unescapeDoubleQuoted(inner[:idx])
+				break
+			}
+			var sb strings.Builder
+			sb.WriteString(inner)
+			closed := false
+			for i+1 < len(lines) {
+				i++
+				sb.WriteByte('\n')
+				if idx := findUnescapedQuote(lines[i]); idx >= 0 {
+					sb.WriteString(lines[i][:idx])
+					closed = true
+					break
+				}
+				sb.WriteString(lines[i])
+			}
+			if !closed {
+				issues = append(issues, LintIssue{Line: start + 1, Rule: RuleUnterminatedQuote,
+					Message: fmt.Sprintf("unterminated double-quoted value for %s", key)})
+			}
+			value = unescapeDoubleQuoted(sb.String())
+		case rawValue != "" && rawValue[0] == '\'':
+			idx := strings.IndexByte(rawValue[1:], '\'')
+			if idx < 0 {
+				issues = append(issues, LintIssue{Line: i + 1, Rule: RuleUnterminatedQuote,
+					Message: fmt.Sprintf("unterminated single-quoted value for %s", key)})
+				value = rawValue[1:]
+				break
+			}
+			value = rawValue[1 : idx+1]
+		default:
+			value = rawValue
+			if rawValue != "" && strings.TrimRight(line, " \t") != line {
+				issues = append(issues, LintIssue{Line: i + 1, Rule: RuleTrailingWhitespace, Fixable: true,
+					Message: fmt.Sprintf("trailing whitespace after the value of %s is ignored", key)})
+				lines[i] = strings.TrimRight(line, " \t")
+			}
+		}
+
+		if n := utf8.RuneCountInString(value); n > MaxValueLength {
+			issues = append(issues, LintIssue{Line: start + 1, Rule: RuleValueTooLarge,
+				Message: fmt.Sprintf("value of %s is %d characters long (GitLab allows %d)", key, n, MaxValueLength)})
+		}
+
+		if key != "" {
+			if prev := definitions[key]; len(prev) > 0 {
+				issues = append(issues, LintIssue{Line: start + 1, Rule: RuleDuplicateKey, Fixable: true,
+					Message: fmt.Sprintf("%s is already defined on line %d; the last definition wins", key, prev[len(prev)-1].start+1)})
+			}
+			definitions[key] = append(definitions[key], span{start, i})
+		}
+	}
+
+	for _, spans := range definitions {
+		for _, s := range spans[:len(spans)-1] {
+			for l := s.start; l <= s.end; l++ {
+				drop[l] = true
+			}
+		}
+	}
+	kept := make([]string, 0, len(lines))
+	for i, l := range lines {
+		if !drop[i] {
+			kept = append(kept, l)
+		}
+	}
+	fixed := strings.Join(kept, "\n")
+	if trailingNewline && len(kept) > 0 {
+		fixed += "\n"
+	}
+	return issues, fixed
+}
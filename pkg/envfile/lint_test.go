@@ -0,0 +1,92 @@
+//nolint:errcheck // test file
+package envfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rules(issues []LintIssue) []string {
+	out := make([]string, 0, len(issues))
+	for _, i := range issues {
+		out = append(out, i.String())
+	}
+	return out
+}
+
+func TestLint_Clean(t *testing.T) {
+	src := "# comment\n\nexport A=1\nB=\"multi\nline\"\nC='x'\n"
+	issues, err := Lint(strings.NewReader(src))
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestLint_Issues(t *testing.T) {
+	src := strings.Join([]string{
+		"DB_HOST=localhost",
+		"just some text",
+		"MY-KEY=1",
+		"PORT=8080  ",
+		"DB_HOST=db.internal",
+		"BIG=" + strings.Repeat("x", MaxValueLength+1),
+		"QUOTED=\"ok\"  ",
+		"",
+	}, "\n")
+
+	issues, err := Lint(strings.NewReader(src))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"2: missing-equals: line has no '=' and is ignored",
+		`3: invalid-key: key "MY-KEY" may only contain letters, digits and '_'`,
+		"4: trailing-whitespace: trailing whitespace after the value of PORT is ignored",
+		"5: duplicate-key: DB_HOST is already defined on line 1; the last definition wins",
+		"6: value-too-large: value of BIG is 10001 characters long (GitLab allows 10000)",
+	}, rules(issues))
+}
+
+func TestLint_KeyTooLong(t *testing.T) {
+	issues, err := Lint(strings.NewReader(strings.Repeat("K", MaxKeyLength+1) + "=v\n"))
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, RuleInvalidKey, issues[0].Rule)
+}
+
+func TestLint_UnterminatedQuote(t *testing.T) {
+	issues, err := Lint(strings.NewReader("A=\"open\nB=2\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1: unterminated-quote: unterminated double-quoted value for A"}, rules(issues))
+}
+
+func TestLint_CRLF(t *testing.T) {
+	issues, err := Lint(strings.NewReader("A=1\r\nB=2\r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1: crlf: file uses CRLF line endings (2 lines)"}, rules(issues))
+}
+
+func TestFix(t *testing.T) {
+	src := "A=1\r\nCERT=\"line1\r\nline2\"\r\nnot a variable\r\nB=2  \r\nCERT=new\r\nMY-KEY=x\r\n"
+
+	fixed, remaining, err := Fix(strings.NewReader(src))
+	require.NoError(t, err)
+	assert.Equal(t, "A=1\n# not a variable\nB=2\nCERT=new\nMY-KEY=x\n", string(fixed))
+	require.Len(t, remaining, 1, "invalid keys are not fixable")
+	assert.Equal(t, RuleInvalidKey, remaining[0].Rule)
+
+	// Fixing does not change what the parser reads, except for dropped duplicates.
+	before, err := ParseReader(strings.NewReader(src))
+	require.NoError(t, err)
+	after, err := ParseReader(strings.NewReader(string(fixed)))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, values(before), values(after))
+}
+
+func values(r *ParseResult) []string {
+	out := make([]string, 0, len(r.Variables))
+	for _, v := range r.Variables {
+		out = append(out, v.Key+"="+v.Value)
+	}
+	return out
+}
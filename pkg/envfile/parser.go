@@ -30,6 +30,7 @@ type SkippedLine struct {
 	Line   int
 	Key    string
 	Reason SkipReason
+	Text   string // the comment itself, for SkipComment
 }
 
 // ParseResult holds the outcome of parsing a .env file.
@@ -45,6 +46,12 @@ var placeholderPatterns = []string{
 	"replace_with_",
 }
 
+// Placeholder returns a placeholder value for key that the parser skips,
+// e.g. "your_db_password" for DB_PASSWORD.
+func Placeholder(key string) string {
+	return "your_" + strings.ToLower(key)
+}
+
 // isPlaceholder returns true if the value appears to be a placeholder.
 func isPlaceholder(value string) bool {
 	lower := strings.ToLower(value)
@@ -129,7 +136,7 @@ func ParseReader(r io.Reader) (*ParseResult, error) {
 
 		// Comment line
 		if strings.HasPrefix(trimmed, "#") {
-			result.Skipped = append(result.Skipped, SkippedLine{Line: lineNum, Reason: SkipComment})
+			result.Skipped = append(result.Skipped, SkippedLine{Line: lineNum, Reason: SkipComment, Text: trimmed})
 			continue
 		}
 
@@ -473,3 +473,20 @@ func TestParseReader_SingleQuotedInterpolation_Skipped(t *testing.T) {
 	require.Len(t, result.Skipped, 1)
 	assert.Equal(t, "KEY", result.Skipped[0].Key)
 }
+
+func TestPlaceholder_SkippedByParser(t *testing.T) {
+	assert.Equal(t, "your_db_password", Placeholder("DB_PASSWORD"))
+
+	result, err := ParseReader(strings.NewReader("DB_PASSWORD=" + Placeholder("DB_PASSWORD") + "\n"))
+	require.NoError(t, err)
+	assert.Empty(t, result.Variables)
+	require.Len(t, result.Skipped, 1)
+	assert.Equal(t, SkipPlaceholder, result.Skipped[0].Reason)
+}
+
+func TestParseReader_CommentText(t *testing.T) {
+	result, err := ParseReader(strings.NewReader("  # Database settings\nDB_HOST=localhost\n"))
+	require.NoError(t, err)
+	require.Len(t, result.Skipped, 1)
+	assert.Equal(t, "# Database settings", result.Skipped[0].Text)
+}
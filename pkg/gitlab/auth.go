@@ -0,0 +1,287 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator adds credentials to API requests.
+type Authenticator interface {
+	// Authenticate sets the credentials on req.
+	Authenticate(ctx context.Context, req *http.Request) error
+	// String names the credential in error messages, never its value.
+	String() string
+}
+
+// Refresher is implemented by authenticators whose credentials can be
+// renewed. Client.Do calls Refresh once after a 401 and retries the request.
+type Refresher interface {
+	// Refresh renews the credentials that failed on req. It does nothing if
+	// they were already renewed by a concurrent request.
+	Refresh(ctx context.Context, req *http.Request) error
+}
+
+// PrivateToken authenticates with a personal, project or group access token.
+type PrivateToken string
+
+// Authenticate sets the PRIVATE-TOKEN header.
+func (t PrivateToken) Authenticate(_ context.Context, req *http.Request) error {
+	req.Header.Set("PRIVATE-TOKEN", string(t))
+	return nil
+}
+
+func (PrivateToken) String() string { return "PRIVATE-TOKEN" }
+
+// JobToken authenticates with the CI_JOB_TOKEN of a running pipeline job.
+type JobToken string
+
+// Authenticate sets the JOB-TOKEN header.
+func (t JobToken) Authenticate(_ context.Context, req *http.Request) error {
+	req.Header.Set("JOB-TOKEN", string(t))
+	return nil
+}
+
+func (JobToken) String() string { return "CI job token" }
+
+// BearerToken authenticates with an OAuth2 access token that is not refreshed.
+type BearerToken string
+
+// Authenticate sets the Authorization header.
+func (t BearerToken) Authenticate(_ context.Context, req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+string(t))
+	return nil
+}
+
+func (BearerToken) String() string { return "OAuth access token" }
+
+// OAuthToken is a token issued by GitLab's OAuth2 provider.
+type OAuthToken struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // seconds, 0 if the token does not expire
+	CreatedAt    int64  `json:"created_at"` // unix seconds
+}
+
+// Expiry returns when the access token expires, or the zero time if it does not.
+func (t *OAuthToken) Expiry() time.Time {
+	if t.ExpiresIn <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(t.CreatedAt, 0).Add(time.Duration(t.ExpiresIn) * time.Second)
+}
+
+// tokenExpiryMargin renews access tokens this long before they expire.
+const tokenExpiryMargin = 30 * time.Second
+
+func (t *OAuthToken) expired(now time.Time) bool {
+	exp := t.Expiry()
+	return !exp.IsZero() && now.After(exp.Add(-tokenExpiryMargin))
+}
+
+// OAuthApp is an OAuth application registered on a GitLab instance.
+type OAuthApp struct {
+	BaseURL  string
+	ClientID string
+	// HTTP sends the OAuth requests. Pass the client given to
+	// ClientConfig.HTTPClient so that token refreshes use the same TLS and
+	// proxy settings as API requests. Defaults to a client without them.
+	HTTP *http.Client
+}
+
+// DeviceCode is the response of a device authorization request.
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// defaultDeviceInterval is the polling interval when the server sends none.
+var defaultDeviceInterval = 5 * time.Second
+
+// oauthError is the error body of the OAuth endpoints.
+type oauthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *oauthError) Error() string {
+	if e.Description != "" {
+		return e.Code + ": " + e.Description
+	}
+	return e.Code
+}
+
+// StartDeviceFlow starts the OAuth2 device authorization grant. The user
+// confirms the returned UserCode at VerificationURI.
+func (a OAuthApp) StartDeviceFlow(ctx context.Context, scopes []string) (*DeviceCode, error) {
+	form := url.Values{"client_id": {a.ClientID}, "scope": {strings.Join(scopes, " ")}}
+	var code DeviceCode
+	if err := a.post(ctx, "start device flow", "/oauth/authorize_device", form, &code); err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// PollDeviceFlow waits until the user confirmed code and returns the issued
+// token. It fails when the user denies access or the code expires.
+func (a OAuthApp) PollDeviceFlow(ctx context.Context, code *DeviceCode) (*OAuthToken, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultDeviceInterval
+	}
+	if code.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	form := url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"device_code": {code.DeviceCode},
+		"client_id":   {a.ClientID},
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("gitlab: device flow: %w", ctx.Err())
+		case <-time.After(interval):
+		}
+
+		var token OAuthToken
+		err := a.post(ctx, "device flow", "/oauth/token", form, &token)
+		var oerr *oauthError
+		switch {
+		case err == nil:
+			token.setCreated(time.Now())
+			return &token, nil
+		case errors.As(err, &oerr) && oerr.Code == "authorization_pending":
+		case errors.As(err, &oerr) && oerr.Code == "slow_down":
+			interval += 5 * time.Second
+		default:
+			return nil, err
+		}
+	}
+}
+
+// Refresh exchanges refreshToken for a new token.
+func (a OAuthApp) Refresh(ctx context.Context, refreshToken string) (*OAuthToken, error) {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {a.ClientID},
+	}
+	var token OAuthToken
+	if err := a.post(ctx, "refresh token", "/oauth/token", form, &token); err != nil {
+		return nil, err
+	}
+	token.setCreated(time.Now())
+	return &token, nil
+}
+
+func (t *OAuthToken) setCreated(now time.Time) {
+	if t.CreatedAt == 0 {
+		t.CreatedAt = now.Unix()
+	}
+}
+
+// post sends a form to an OAuth endpoint and decodes the JSON response.
+// OAuth errors are returned as *oauthError.
+func (a OAuthApp) post(ctx context.Context, op, path string, form url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(a.BaseURL, "/")+path,
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("gitlab: %s: build request: %w", op, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	client := a.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	resp, err := client.Do(req) //nolint:gosec // G704: Not SSRF - URL comes from trusted config
+	if err != nil {
+		return fmt.Errorf("gitlab: %s: %w", op, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		var oerr oauthError
+		if json.NewDecoder(resp.Body).Decode(&oerr) == nil && oerr.Code != "" {
+			return fmt.Errorf("gitlab: %s: %w", op, &oerr)
+		}
+		return fmt.Errorf("gitlab: %s: unexpected status %d", op, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("gitlab: %s: decode: %w", op, err)
+	}
+	return nil
+}
+
+// OAuth authenticates with an OAuth2 access token and refreshes it when it
+// expires or is rejected. It is safe for concurrent use.
+type OAuth struct {
+	app OAuthApp
+	// onRefresh is called with every renewed token, e.g. to persist it.
+	onRefresh func(*OAuthToken)
+
+	mu    sync.Mutex
+	token *OAuthToken
+}
+
+// NewOAuth returns an authenticator for token. onRefresh may be nil.
+func NewOAuth(app OAuthApp, token *OAuthToken, onRefresh func(*OAuthToken)) *OAuth {
+	return &OAuth{app: app, token: token, onRefresh: onRefresh}
+}
+
+// Authenticate sets the Authorization header, refreshing an expired token first.
+func (o *OAuth) Authenticate(ctx context.Context, req *http.Request) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.token.expired(time.Now()) && o.token.RefreshToken != "" {
+		if err := o.refreshLocked(ctx); err != nil {
+			return err
+		}
+	}
+	req.Header.Set("Authorization", "Bearer "+o.token.AccessToken)
+	return nil
+}
+
+// Refresh renews the token unless req was sent with an older token than the
+// current one.
+func (o *OAuth) Refresh(ctx context.Context, req *http.Request) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if req.Header.Get("Authorization") != "Bearer "+o.token.AccessToken {
+		return nil
+	}
+	return o.refreshLocked(ctx)
+}
+
+func (o *OAuth) refreshLocked(ctx context.Context) error {
+	if o.token.RefreshToken == "" {
+		return errors.New("gitlab: refresh token: no refresh token")
+	}
+	token, err := o.app.Refresh(ctx, o.token.RefreshToken)
+	if err != nil {
+		return err
+	}
+	o.token = token
+	if o.onRefresh != nil {
+		o.onRefresh(token)
+	}
+	return nil
+}
+
+func (*OAuth) String() string { return "OAuth access token" }
@@ -0,0 +1,215 @@
+//nolint:errcheck // test file
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAuthClient returns a client for srv authenticating with auth.
+func newAuthClient(srv *httptest.Server, auth Authenticator) *Client {
+	return NewClient(ClientConfig{
+		BaseURL:             srv.URL,
+		RequestsPerSecond:   100,
+		RetryInitialBackoff: time.Millisecond,
+		Auth:                auth,
+	})
+}
+
+func TestAuthenticators_Headers(t *testing.T) {
+	tests := []struct {
+		auth         Authenticator
+		header, want string
+	}{
+		{PrivateToken("pat"), "PRIVATE-TOKEN", "pat"},
+		{JobToken("job"), "JOB-TOKEN", "job"},
+		{BearerToken("access"), "Authorization", "Bearer access"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.auth.String(), func(t *testing.T) {
+			var got http.Header
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Clone()
+			}))
+			defer srv.Close()
+
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/v4/user", http.NoBody)
+			resp, err := newAuthClient(srv, tt.auth).Do(context.Background(), req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tt.want, got.Get(tt.header))
+			if tt.header != "PRIVATE-TOKEN" {
+				assert.Empty(t, got.Get("PRIVATE-TOKEN"))
+			}
+		})
+	}
+}
+
+func TestDo_401_MentionsCredential(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/v4/user", http.NoBody)
+	_, err := newAuthClient(srv, JobToken("job")).Do(context.Background(), req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CI job token")
+	assert.NotContains(t, err.Error(), "job\"")
+}
+
+// oauthServer serves the API, accepting only the current access token, and
+// the token endpoint, which rotates it on every refresh.
+type oauthServer struct {
+	current   atomic.Value // string
+	refreshes atomic.Int32
+}
+
+func (s *oauthServer) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/token":
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "refresh_token", r.Form.Get("grant_type"))
+			assert.Equal(t, "app-id", r.Form.Get("client_id"))
+			if r.Form.Get("refresh_token") != "refresh-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+			s.refreshes.Add(1)
+			access := "access-new"
+			s.current.Store(access)
+			json.NewEncoder(w).Encode(OAuthToken{AccessToken: access, RefreshToken: "refresh-2", ExpiresIn: 7200, CreatedAt: time.Now().Unix()})
+		default:
+			if r.Header.Get("Authorization") != "Bearer "+s.current.Load().(string) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{}`))
+		}
+	}
+}
+
+func TestOAuth_RefreshOn401(t *testing.T) {
+	s := &oauthServer{}
+	s.current.Store("access-new")
+	srv := httptest.NewServer(s.handler(t))
+	defer srv.Close()
+
+	var saved *OAuthToken
+	auth := NewOAuth(OAuthApp{BaseURL: srv.URL, ClientID: "app-id"},
+		&OAuthToken{AccessToken: "access-revoked", RefreshToken: "refresh-1"},
+		func(tok *OAuthToken) { saved = tok })
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/v4/user", http.NoBody)
+	resp, err := newAuthClient(srv, auth).Do(context.Background(), req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(1), s.refreshes.Load())
+	require.NotNil(t, saved)
+	assert.Equal(t, "refresh-2", saved.RefreshToken)
+}
+
+func TestOAuth_RefreshWhenExpired(t *testing.T) {
+	s := &oauthServer{}
+	s.current.Store("access-new")
+	srv := httptest.NewServer(s.handler(t))
+	defer srv.Close()
+
+	expired := &OAuthToken{AccessToken: "access-old", RefreshToken: "refresh-1", ExpiresIn: 60,
+		CreatedAt: time.Now().Add(-time.Hour).Unix()}
+	client := newAuthClient(srv, NewOAuth(OAuthApp{BaseURL: srv.URL, ClientID: "app-id"}, expired, nil))
+
+	// Concurrent requests refresh the expired token only once.
+	done := make(chan error)
+	for range 5 {
+		go func() {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/v4/user", http.NoBody)
+			resp, err := client.Do(context.Background(), req)
+			if err == nil {
+				resp.Body.Close()
+			}
+			done <- err
+		}()
+	}
+	for range 5 {
+		require.NoError(t, <-done)
+	}
+	assert.Equal(t, int32(1), s.refreshes.Load())
+}
+
+func TestOAuth_RefreshFails(t *testing.T) {
+	s := &oauthServer{}
+	s.current.Store("access-new")
+	srv := httptest.NewServer(s.handler(t))
+	defer srv.Close()
+
+	auth := NewOAuth(OAuthApp{BaseURL: srv.URL, ClientID: "app-id"},
+		&OAuthToken{AccessToken: "access-revoked", RefreshToken: "stale"}, nil)
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/v4/user", http.NoBody)
+	_, err := newAuthClient(srv, auth).Do(context.Background(), req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid_grant")
+}
+
+func TestDeviceFlow(t *testing.T) {
+	old := defaultDeviceInterval
+	defaultDeviceInterval = time.Millisecond
+	t.Cleanup(func() { defaultDeviceInterval = old })
+
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		switch r.URL.Path {
+		case "/oauth/authorize_device":
+			assert.Equal(t, "app-id", r.Form.Get("client_id"))
+			assert.Equal(t, "api read_user", r.Form.Get("scope"))
+			w.Write([]byte(`{"device_code":"dev","user_code":"ABCD-EFGH","verification_uri":"https://gitlab.example/oauth/device","expires_in":300}`))
+		case "/oauth/token":
+			assert.Equal(t, "dev", r.Form.Get("device_code"))
+			if polls.Add(1) < 3 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"authorization_pending"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"access","refresh_token":"refresh","expires_in":7200}`))
+		}
+	}))
+	defer srv.Close()
+
+	app := OAuthApp{BaseURL: srv.URL, ClientID: "app-id"}
+	code, err := app.StartDeviceFlow(context.Background(), []string{"api", "read_user"})
+	require.NoError(t, err)
+	assert.Equal(t, "ABCD-EFGH", code.UserCode)
+
+	token, err := app.PollDeviceFlow(context.Background(), code)
+	require.NoError(t, err)
+	assert.Equal(t, "access", token.AccessToken)
+	assert.Equal(t, int32(3), polls.Load())
+	assert.False(t, token.Expiry().IsZero())
+}
+
+func TestDeviceFlow_Denied(t *testing.T) {
+	old := defaultDeviceInterval
+	defaultDeviceInterval = time.Millisecond
+	t.Cleanup(func() { defaultDeviceInterval = old })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"access_denied","error_description":"The resource owner denied the request"}`))
+	}))
+	defer srv.Close()
+
+	_, err := OAuthApp{BaseURL: srv.URL}.PollDeviceFlow(context.Background(), &DeviceCode{DeviceCode: "dev"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access_denied: The resource owner denied the request")
+}
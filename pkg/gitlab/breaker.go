@@ -0,0 +1,135 @@
+package gitlab
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting GitLab while the circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Circuit breaker states.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// BreakerState is a snapshot of the client's circuit breaker.
+type BreakerState struct {
+	State               string
+	ConsecutiveFailures int
+	Trips               int       // how often the breaker opened
+	OpenedAt            time.Time // when the breaker last opened
+	LastError           string    // the failure that last opened the breaker
+}
+
+// breaker stops sending requests after threshold consecutive network errors
+// or 5xx responses. After cooldown it lets a single probe request through; a
+// successful probe closes it again, a failed one reopens it.
+type breaker struct {
+	threshold int // 0 disables the breaker
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	state   BreakerState
+	probing bool
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     BreakerState{State: BreakerClosed},
+	}
+}
+
+// allow returns ErrCircuitOpen (wrapped with details) if no request may be
+// sent. probe reports whether the caller was let through as the single
+// half-open probe; it must then call success, failure or abort.
+func (b *breaker) allow() (probe bool, err error) {
+	if b.threshold <= 0 {
+		return false, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state.State {
+	case BreakerOpen:
+		if b.now().Before(b.state.OpenedAt.Add(b.cooldown)) {
+			return false, b.openErr()
+		}
+		b.state.State = BreakerHalfOpen
+	case BreakerHalfOpen:
+		if b.probing {
+			return false, b.openErr()
+		}
+	default:
+		return false, nil
+	}
+	b.probing = true
+	return true, nil
+}
+
+// abort releases the probe of a request that ended without an outcome, e.g.
+// because its context was canceled, so that the next request can probe.
+func (b *breaker) abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state.State == BreakerHalfOpen {
+		b.probing = false
+	}
+}
+
+func (b *breaker) openErr() error {
+	return fmt.Errorf("gitlab: %w: GitLab looks unavailable after %d consecutive failures (last: %s); not sending requests until %s",
+		ErrCircuitOpen, b.state.ConsecutiveFailures, b.state.LastError, b.state.OpenedAt.Add(b.cooldown).Format(time.TimeOnly))
+}
+
+// success records a response from GitLab that was not a server error.
+func (b *breaker) success() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state.ConsecutiveFailures = 0
+	b.state.State = BreakerClosed
+	b.probing = false
+}
+
+// failure records a network error or 5xx response and reports whether it
+// opened the breaker.
+func (b *breaker) failure(err error) bool {
+	if b.threshold <= 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state.ConsecutiveFailures++
+	if b.state.State == BreakerHalfOpen || (b.state.State == BreakerClosed && b.state.ConsecutiveFailures >= b.threshold) {
+		b.state.State = BreakerOpen
+		b.state.OpenedAt = b.now()
+		b.state.LastError = err.Error()
+		b.state.Trips++
+		b.probing = false
+		return true
+	}
+	b.probing = false
+	return false
+}
+
+func (b *breaker) snapshot() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// BreakerState returns the current state of the circuit breaker.
+func (c *Client) BreakerState() BreakerState {
+	return c.breaker.snapshot()
+}
@@ -0,0 +1,162 @@
+//nolint:errcheck // test file
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker_TripsAndRecovers(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := newBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	require.NoError(t, allowErr(b))
+	b.failure(errors.New("server error 502"))
+	require.NoError(t, allowErr(b))
+	b.failure(errors.New("server error 503"))
+
+	err := allowErr(b)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Contains(t, err.Error(), "server error 503")
+	assert.Equal(t, BreakerOpen, b.snapshot().State)
+	assert.Equal(t, 1, b.snapshot().Trips)
+
+	now = now.Add(time.Minute)
+	probe, err := b.allow()
+	require.NoError(t, err, "one probe after the cooldown")
+	assert.True(t, probe)
+	assert.True(t, errors.Is(allowErr(b), ErrCircuitOpen), "only one probe at a time")
+	b.failure(errors.New("server error 502"))
+	assert.Equal(t, BreakerOpen, b.snapshot().State, "failed probe reopens")
+	assert.Equal(t, 2, b.snapshot().Trips)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, allowErr(b))
+	b.success()
+	assert.Equal(t, BreakerClosed, b.snapshot().State)
+	assert.Equal(t, 0, b.snapshot().ConsecutiveFailures)
+	probe, err = b.allow()
+	require.NoError(t, err)
+	assert.False(t, probe, "no probe while closed")
+}
+
+func TestBreaker_AbortReleasesProbe(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := newBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+	b.failure(errors.New("boom"))
+
+	now = now.Add(time.Minute)
+	require.NoError(t, allowErr(b))
+	b.abort()
+	require.NoError(t, allowErr(b), "the next request probes after an aborted probe")
+}
+
+// allowErr returns only the error of b.allow.
+func allowErr(b *breaker) error {
+	_, err := b.allow()
+	return err
+}
+
+func TestBreaker_SuccessResetsCount(t *testing.T) {
+	b := newBreaker(2, time.Minute)
+	b.failure(errors.New("boom"))
+	b.success()
+	b.failure(errors.New("boom"))
+	assert.NoError(t, allowErr(b))
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	b := newBreaker(0, time.Minute)
+	for range 10 {
+		b.failure(errors.New("boom"))
+	}
+	assert.NoError(t, allowErr(b))
+	assert.Equal(t, BreakerClosed, b.snapshot().State)
+}
+
+func TestDo_CircuitBreakerFailsFast(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	client := NewClient(ClientConfig{BaseURL: srv.URL, Token: "t", RequestsPerSecond: 100,
+		RetryMax: 5, RetryInitialBackoff: time.Millisecond, BreakerThreshold: 3, BreakerCooldown: time.Hour})
+
+	_, err := client.ListVariables(context.Background(), "1", ListOptions{})
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int32(3), calls.Load(), "retries stop once the breaker opens")
+
+	_, err = client.ListVariables(context.Background(), "1", ListOptions{})
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int32(3), calls.Load(), "later requests are not sent")
+
+	state := client.BreakerState()
+	assert.Equal(t, BreakerOpen, state.State)
+	assert.Equal(t, 1, state.Trips)
+}
+
+func TestDo_CircuitBreakerReleasesProbeOnEarlyReturn(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+	var failAuth atomic.Bool
+	client := NewClient(ClientConfig{BaseURL: srv.URL, RequestsPerSecond: 100, BreakerThreshold: 1,
+		BreakerCooldown: time.Millisecond, Auth: authFunc(func(*http.Request) error {
+			if failAuth.Load() {
+				return errors.New("refresh failed")
+			}
+			return nil
+		})})
+	client.breaker.failure(errors.New("server error 502"))
+	time.Sleep(2 * time.Millisecond)
+
+	failAuth.Store(true)
+	_, err := client.ListVariables(context.Background(), "1", ListOptions{})
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrCircuitOpen), "the probe itself fails on authentication")
+
+	failAuth.Store(false)
+	_, err = client.ListVariables(context.Background(), "1", ListOptions{})
+	require.NoError(t, err, "the probe was released, so the next request is sent")
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, BreakerClosed, client.BreakerState().State)
+}
+
+// authFunc is an Authenticator backed by a function.
+type authFunc func(*http.Request) error
+
+func (f authFunc) Authenticate(_ context.Context, req *http.Request) error { return f(req) }
+func (authFunc) String() string                                            { return "test auth" }
+
+func TestDo_CircuitBreakerIgnoresClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	client := NewClient(ClientConfig{BaseURL: srv.URL, Token: "t", RequestsPerSecond: 100, BreakerThreshold: 1})
+
+	for range 3 {
+		_, err := client.GetVariable(context.Background(), "1", "KEY", "")
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, BreakerClosed, client.BreakerState().State)
+}
@@ -3,15 +3,16 @@ package gitlab
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"math/rand/v2"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
-
-	"golang.org/x/time/rate"
 )
 
 // ClientConfig holds configuration for the GitLab HTTP client.
@@ -23,13 +24,35 @@ type ClientConfig struct {
 	RetryMax            int
 	RetryInitialBackoff time.Duration
 	HTTPClient          *http.Client
+	// Auth authenticates requests; defaults to PrivateToken(Token).
+	Auth Authenticator
+	// OnRateChange is called when the adaptive limiter changes its rate, in
+	// addition to an info record on Logger.
+	OnRateChange func(old, updated LimiterState)
+	// RetryBudget caps the network error and 5xx retries of all requests
+	// together, so that a flaky network fails a large sync quickly instead of
+	// retrying every request RetryMax times. 429 retries are not counted.
+	// 0 means no cap.
+	RetryBudget int
+	// BreakerThreshold is the number of consecutive network errors or 5xx
+	// responses after which requests fail fast with ErrCircuitOpen for
+	// BreakerCooldown. 0 disables the circuit breaker.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// Logger receives request, retry and circuit breaker records; nil
+	// disables logging. Tokens and variable values are never logged.
+	Logger *slog.Logger
 }
 
-// Client is a rate-limited, retry-aware HTTP client for the GitLab API.
+// Client is a rate-limited, retry-aware HTTP client for the GitLab API. The
+// rate adapts to GitLab's RateLimit-* response headers, see LimiterState.
 type Client struct {
 	cfg     ClientConfig
-	limiter *rate.Limiter
+	limiter *adaptiveLimiter
+	budget  *retryBudget
+	breaker *breaker
 	http    *http.Client
+	log     *slog.Logger
 }
 
 // NewClient creates a new Client with the given configuration.
@@ -49,24 +72,66 @@ func NewClient(cfg ClientConfig) *Client {
 		cfg.RetryInitialBackoff = 1 * time.Second
 	}
 
+	if cfg.BreakerCooldown <= 0 {
+		cfg.BreakerCooldown = 30 * time.Second
+	}
+	if cfg.Auth == nil {
+		cfg.Auth = PrivateToken(cfg.Token)
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = discardLogger
+	}
+
 	httpClient := cfg.HTTPClient
 	if httpClient == nil {
-		httpClient = &http.Client{Timeout: 30 * time.Second}
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+
+	onRateChange := func(old, updated LimiterState) {
+		logRateChange(logger, old, updated)
+		if cfg.OnRateChange != nil {
+			cfg.OnRateChange(old, updated)
+		}
 	}
 
 	return &Client{
 		cfg:     cfg,
-		limiter: rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), cfg.Burst),
+		limiter: newAdaptiveLimiter(cfg.RequestsPerSecond, cfg.Burst, onRateChange),
+		budget:  newRetryBudget(cfg.RetryBudget),
+		breaker: newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
 		http:    httpClient,
+		log:     logger,
 	}
 }
 
 // Do executes an HTTP request with rate limiting, retry, and backoff.
-// The PRIVATE-TOKEN header is injected automatically.
-// 401 responses are returned immediately without retry.
+// Credentials are added by ClientConfig.Auth (PRIVATE-TOKEN by default).
+// 401 responses are returned immediately without retry, unless the
+// authenticator can refresh its credentials; then the request is retried once.
 // 429 responses are retried after honoring the Retry-After header.
 // Network errors are retried up to RetryMax times with exponential backoff.
+// Retries of network errors and 5xx across all requests are limited by
+// ClientConfig.RetryBudget.
 func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return c.do(ctx, req, sendOptions{})
+}
+
+// sendOptions controls how do treats attempts that may have reached GitLab
+// (a network error or a 5xx response), after which a non-idempotent request
+// may have been applied already.
+type sendOptions struct {
+	// resent, if not nil, is set when the request was sent again after such
+	// an attempt.
+	resent *bool
+	// once disables retrying after such an attempt, for requests that must
+	// not be applied twice and cannot be recognized as duplicates.
+	once bool
+}
+
+// do implements Do.
+func (c *Client) do(ctx context.Context, req *http.Request, opts sendOptions) (*http.Response, error) {
 	// Clone to avoid mutating the caller's request (token must not leak via shared headers).
 	req = req.Clone(ctx)
 
@@ -81,12 +146,32 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 		_ = req.Body.Close()
 	}
 
-	req.Header.Set("PRIVATE-TOKEN", c.cfg.Token)
-
 	var lastErr error
+	refreshed := false
+	// probe is set while this request holds the circuit breaker's half-open
+	// probe; it is released if the request ends without success or failure.
+	probe := false
+	defer func() {
+		if probe {
+			c.breaker.abort()
+		}
+	}()
 	for attempt := 0; attempt <= c.cfg.RetryMax; attempt++ {
+		// Fail fast while GitLab is down, also between retries.
+		var err error
+		if probe, err = c.breaker.allow(); err != nil {
+			return nil, err
+		}
+
 		// Wait for the rate limiter.
+		waitStart := time.Now()
 		if err := c.limiter.Wait(ctx); err != nil {
+			// The limiter fails early when the wait would outlast ctx's
+			// deadline; report that as a deadline error so callers can
+			// detect timeouts with errors.Is.
+			if _, ok := ctx.Deadline(); ok && ctx.Err() == nil {
+				err = fmt.Errorf("%w: %v", context.DeadlineExceeded, err)
+			}
 			return nil, fmt.Errorf("gitlab: rate limiter: %w", err)
 		}
 
@@ -95,12 +180,33 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 			req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 			req.ContentLength = int64(len(bodyBytes))
 		}
+		if err := c.cfg.Auth.Authenticate(ctx, req); err != nil {
+			return nil, fmt.Errorf("gitlab: authenticate: %w", err)
+		}
+
+		wait := time.Since(waitStart)
 
+		sent := time.Now()
 		resp, err := c.http.Do(req) //nolint:gosec // G704: Not SSRF - URL comes from trusted config
+		latency := time.Since(sent)
 		if err != nil {
+			c.log.Debug("gitlab: request failed", append(requestAttrs(req, attempt),
+				slog.Duration("latency", latency), slog.Duration("wait", wait), slog.Any("error", err))...)
 			lastErr = err
+			if ctx.Err() == nil {
+				c.recordFailure(err)
+				probe = false
+			}
+			if opts.once {
+				return nil, fmt.Errorf("gitlab: request failed and was not retried, it may have been applied: %w", err)
+			}
 			if attempt < c.cfg.RetryMax {
+				if !c.budget.take() {
+					return nil, c.budget.exhausted(err)
+				}
+				markResent(opts.resent)
 				sleep := c.backoff(attempt, 0)
+				c.logRetry(req, attempt, err.Error(), sleep)
 				select {
 				case <-ctx.Done():
 					return nil, ctx.Err()
@@ -109,19 +215,42 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 			}
 			continue
 		}
+		c.log.Debug("gitlab: request", append(requestAttrs(req, attempt), slog.Int("status", resp.StatusCode),
+			slog.Duration("latency", latency), slog.Duration("wait", wait))...)
+		c.limiter.observe(resp.Header)
+		if resp.StatusCode >= 500 {
+			c.recordFailure(fmt.Errorf("server error %d", resp.StatusCode))
+		} else {
+			c.breaker.success()
+		}
+		probe = false
 
-		// 401: do not retry — return a clear authentication error.
+		// 401: refresh once if possible, otherwise return a clear
+		// authentication error.
 		if resp.StatusCode == http.StatusUnauthorized {
 			_ = resp.Body.Close()
-			return nil, fmt.Errorf("gitlab: authentication failed (HTTP 401): verify your PRIVATE-TOKEN")
+			if r, ok := c.cfg.Auth.(Refresher); ok && !refreshed {
+				if err := r.Refresh(ctx, req); err != nil {
+					return nil, fmt.Errorf("gitlab: authentication failed (HTTP 401): %w", err)
+				}
+				c.log.Info("gitlab: refreshed credentials after 401", slog.Any("auth", c.cfg.Auth))
+				refreshed = true
+				attempt-- // the refresh retry does not count against RetryMax
+				continue
+			}
+			return nil, fmt.Errorf("gitlab: authentication failed (HTTP 401): verify your %s", c.cfg.Auth)
 		}
 
 		// 429: respect Retry-After, then retry.
 		if resp.StatusCode == http.StatusTooManyRequests {
+			c.limiter.throttle()
 			extra := c.parseRetryAfter(resp)
 			_ = resp.Body.Close()
+			// 429s do not draw from the retry budget: the adaptive limiter
+			// already slows down, and a rate-limited sync is not a flaky one.
 			if attempt < c.cfg.RetryMax {
 				sleep := c.backoff(attempt, extra)
+				c.logRetry(req, attempt, "rate limited", sleep)
 				select {
 				case <-ctx.Done():
 					return nil, ctx.Err()
@@ -136,8 +265,16 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 		if resp.StatusCode >= 500 {
 			_ = resp.Body.Close()
 			lastErr = fmt.Errorf("gitlab: server error %d", resp.StatusCode)
+			if opts.once {
+				return nil, fmt.Errorf("gitlab: server error %d, not retried: the request may have been applied", resp.StatusCode)
+			}
 			if attempt < c.cfg.RetryMax {
+				if !c.budget.take() {
+					return nil, c.budget.exhausted(lastErr)
+				}
+				markResent(opts.resent)
 				sleep := c.backoff(attempt, 0)
+				c.logRetry(req, attempt, fmt.Sprintf("server error %d", resp.StatusCode), sleep)
 				select {
 				case <-ctx.Done():
 					return nil, ctx.Err()
@@ -157,6 +294,26 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 	return nil, fmt.Errorf("gitlab: request failed after %d attempts", c.cfg.RetryMax+1)
 }
 
+// recordFailure counts a network error or 5xx towards the circuit breaker.
+func (c *Client) recordFailure(err error) {
+	if c.breaker.failure(err) {
+		state := c.breaker.snapshot()
+		c.log.Warn("gitlab: circuit breaker opened", slog.Int("failures", state.ConsecutiveFailures),
+			slog.Duration("cooldown", c.cfg.BreakerCooldown), slog.String("last_error", state.LastError))
+	}
+}
+
+func markResent(resent *bool) {
+	if resent != nil {
+		*resent = true
+	}
+}
+
+// LimiterState returns the current state of the adaptive rate limiter.
+func (c *Client) LimiterState() LimiterState {
+	return c.limiter.snapshot()
+}
+
 // maxBackoff is the upper bound for any computed backoff duration.
 const maxBackoff = 5 * time.Minute
 
@@ -202,3 +359,129 @@ func (c *Client) parseRetryAfter(resp *http.Response) time.Duration {
 	}
 	return d
 }
+
+// maxPages bounds listPaged to guard against pagination loops.
+const maxPages = 1000
+
+// listPaged GETs path with query q and decodes every page into a single slice,
+// following the X-Next-Page header. op names the operation in error messages.
+// page and perPage default to 1 and 100 when zero.
+func listPaged[T any](ctx context.Context, c *Client, op, path string, q url.Values, page, perPage int) ([]T, error) {
+	if perPage <= 0 {
+		perPage = 100
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if q == nil {
+		q = url.Values{}
+	}
+
+	var all []T
+	for pageNum := 0; pageNum < maxPages; pageNum++ {
+		q.Set("per_page", strconv.Itoa(perPage))
+		q.Set("page", strconv.Itoa(page))
+
+		apiURL := fmt.Sprintf("%s%s?%s", c.cfg.BaseURL, path, q.Encode())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
+		if err != nil {
+			return nil, fmt.Errorf("gitlab: %s: build request: %w", op, err)
+		}
+
+		resp, err := c.Do(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("gitlab: %s: %w", op, err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			err := statusError(op, resp)
+			_ = resp.Body.Close()
+			return nil, err
+		}
+
+		var items []T
+		decodeErr := json.NewDecoder(resp.Body).Decode(&items)
+		_ = resp.Body.Close()
+		if decodeErr != nil {
+			return nil, fmt.Errorf("gitlab: %s: decode: %w", op, decodeErr)
+		}
+		all = append(all, items...)
+
+		nextPage := resp.Header.Get("X-Next-Page")
+		if nextPage == "" || nextPage == "0" {
+			return all, nil
+		}
+		n, err := strconv.Atoi(nextPage)
+		if err != nil || n <= page {
+			return all, nil
+		}
+		page = n
+	}
+
+	return nil, fmt.Errorf("gitlab: %s: exceeded %d pages; possible pagination loop", op, maxPages)
+}
+
+// getJSON GETs path and decodes the response body into out.
+func (c *Client) getJSON(ctx context.Context, op, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.BaseURL+path, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("gitlab: %s: build request: %w", op, err)
+	}
+
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		return fmt.Errorf("gitlab: %s: %w", op, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(op, resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("gitlab: %s: decode: %w", op, err)
+	}
+	return nil
+}
+
+// postJSON POSTs in as JSON to path, expects 201 Created and decodes the
+// response body into out. opts is passed on to do.
+func (c *Client) postJSON(ctx context.Context, op, path string, in, out any, opts sendOptions) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("gitlab: %s: encode: %w", op, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("gitlab: %s: build request: %w", op, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(ctx, req, opts)
+	if err != nil {
+		return fmt.Errorf("gitlab: %s: %w", op, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusCreated {
+		return statusError(op, resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("gitlab: %s: decode: %w", op, err)
+	}
+	return nil
+}
+
+// statusError builds the error for an unexpected response status, wrapping
+// ErrNotFound or ErrForbidden where applicable.
+func statusError(op string, resp *http.Response) error {
+	msg := readErrorBody(resp)
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("gitlab: %s: %w (HTTP 404)%s", op, ErrNotFound, msg)
+	case http.StatusForbidden:
+		return fmt.Errorf("gitlab: %s: %w (HTTP 403)%s", op, ErrForbidden, msg)
+	default:
+		return fmt.Errorf("gitlab: %s: unexpected status %d%s", op, resp.StatusCode, msg)
+	}
+}
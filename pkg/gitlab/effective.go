@@ -0,0 +1,59 @@
+package gitlab
+
+import "sort"
+
+// Layer is a set of variables defined at one level of the GitLab hierarchy,
+// e.g. the instance, a group or the project.
+type Layer struct {
+	Name      string
+	Variables []Variable
+}
+
+// Shadow is a variable hidden by a higher-precedence definition of the same key.
+type Shadow struct {
+	Layer    string
+	Variable Variable
+}
+
+// EffectiveVariable is the definition of a key a job actually sees, together
+// with the definitions it overrides.
+type EffectiveVariable struct {
+	Variable
+	Layer string
+	// Shadowed lists the overridden definitions, highest precedence first.
+	Shadowed []Shadow
+}
+
+// ResolveLayers applies GitLab's precedence rules to layers for jobs running in
+// environment. layers must be ordered from lowest to highest precedence
+// (instance, top-level group … closest group, project): a later layer always
+// wins over an earlier one, and within a layer the most specific matching
+// scope wins (see MoreSpecific). The result is sorted by key.
+func ResolveLayers(layers []Layer, environment string) []EffectiveVariable {
+	byKey := make(map[string][]Shadow)
+	for i := len(layers) - 1; i >= 0; i-- {
+		candidates := make([]Variable, 0, len(layers[i].Variables))
+		for _, v := range layers[i].Variables {
+			if ScopeMatches(v.EnvironmentScope, environment) {
+				candidates = append(candidates, v)
+			}
+		}
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return MoreSpecific(candidates[a].EnvironmentScope, candidates[b].EnvironmentScope, environment)
+		})
+		for _, v := range candidates {
+			byKey[v.Key] = append(byKey[v.Key], Shadow{Layer: layers[i].Name, Variable: v})
+		}
+	}
+
+	result := make([]EffectiveVariable, 0, len(byKey))
+	for _, defs := range byKey {
+		result = append(result, EffectiveVariable{
+			Variable: defs[0].Variable,
+			Layer:    defs[0].Layer,
+			Shadowed: defs[1:],
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
@@ -0,0 +1,47 @@
+package gitlab
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveLayers(t *testing.T) {
+	layers := []Layer{
+		{Name: "instance", Variables: []Variable{
+			{Key: "LOG_LEVEL", Value: "warn", EnvironmentScope: "*"},
+			{Key: "REGION", Value: "eu", EnvironmentScope: "*"},
+		}},
+		{Name: "group:acme", Variables: []Variable{
+			{Key: "LOG_LEVEL", Value: "info", EnvironmentScope: "*"},
+			{Key: "DB_URL", Value: "group-prod", EnvironmentScope: "production"},
+		}},
+		{Name: "project", Variables: []Variable{
+			{Key: "LOG_LEVEL", Value: "debug", EnvironmentScope: "staging"},
+			{Key: "DB_URL", Value: "project-global", EnvironmentScope: "*"},
+			{Key: "DB_URL", Value: "project-prod", EnvironmentScope: "production"},
+		}},
+	}
+
+	got := ResolveLayers(layers, "production")
+	require.Len(t, got, 3)
+
+	assert.Equal(t, "DB_URL", got[0].Key)
+	assert.Equal(t, "project-prod", got[0].Value)
+	assert.Equal(t, "project", got[0].Layer)
+	require.Len(t, got[0].Shadowed, 2)
+	assert.Equal(t, "project-global", got[0].Shadowed[0].Variable.Value)
+	assert.Equal(t, "group:acme", got[0].Shadowed[1].Layer)
+
+	// The project's staging-only LOG_LEVEL does not apply to production.
+	assert.Equal(t, "LOG_LEVEL", got[1].Key)
+	assert.Equal(t, "info", got[1].Value)
+	assert.Equal(t, "group:acme", got[1].Layer)
+	require.Len(t, got[1].Shadowed, 1)
+	assert.Equal(t, "instance", got[1].Shadowed[0].Layer)
+
+	assert.Equal(t, "REGION", got[2].Key)
+	assert.Equal(t, "instance", got[2].Layer)
+	assert.Empty(t, got[2].Shadowed)
+}
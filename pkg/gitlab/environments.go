@@ -0,0 +1,52 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Environment represents a GitLab project environment.
+type Environment struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	State       string `json:"state"`
+	ExternalURL string `json:"external_url"`
+}
+
+// ListEnvironments returns all environments of the given project, following pagination.
+func (c *Client) ListEnvironments(ctx context.Context, projectID string) ([]Environment, error) {
+	path := fmt.Sprintf("/api/v4/projects/%s/environments", url.PathEscape(projectID))
+	return listPaged[Environment](ctx, c, "list environments", path, nil, 0, 0)
+}
+
+// CreateEnvironment creates an environment named name in the given project.
+func (c *Client) CreateEnvironment(ctx context.Context, projectID, name string) (*Environment, error) {
+	var env Environment
+	path := fmt.Sprintf("/api/v4/projects/%s/environments", url.PathEscape(projectID))
+	if err := c.postJSON(ctx, "create environment", path, map[string]string{"name": name}, &env, sendOptions{}); err != nil {
+		return nil, err
+	}
+	return &env, nil
+}
+
+// ScopeHasEnvironment reports whether a variable scoped to scope would apply to
+// at least one of envs. "*" always qualifies; other scopes, including wildcard
+// patterns such as "review/*", must match an existing environment name.
+func ScopeHasEnvironment(scope string, envs []Environment) bool {
+	if scope == WildcardScope {
+		return true
+	}
+	for _, env := range envs {
+		if ScopeMatches(scope, env.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWildcardScope reports whether scope is a pattern rather than a single environment name.
+func IsWildcardScope(scope string) bool {
+	return strings.Contains(scope, "*")
+}
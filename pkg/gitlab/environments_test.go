@@ -0,0 +1,52 @@
+//nolint:errcheck // test file
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListEnvironments(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v4/projects/42/environments", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]Environment{{ID: 1, Name: "production", State: "available"}})
+	})
+
+	envs, err := client.ListEnvironments(context.Background(), "42")
+	require.NoError(t, err)
+	require.Len(t, envs, 1)
+	assert.Equal(t, "production", envs[0].Name)
+}
+
+func TestCreateEnvironment(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v4/projects/42/environments", r.URL.Path)
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "staging", body["name"])
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Environment{ID: 2, Name: "staging", State: "available"})
+	})
+
+	env, err := client.CreateEnvironment(context.Background(), "42", "staging")
+	require.NoError(t, err)
+	assert.Equal(t, 2, env.ID)
+}
+
+func TestScopeHasEnvironment(t *testing.T) {
+	envs := []Environment{{Name: "production"}, {Name: "review/feature-x"}}
+
+	assert.True(t, ScopeHasEnvironment("*", nil))
+	assert.True(t, ScopeHasEnvironment("production", envs))
+	assert.True(t, ScopeHasEnvironment("review/*", envs))
+	assert.False(t, ScopeHasEnvironment("prodution", envs))
+	assert.False(t, ScopeHasEnvironment("staging/*", envs))
+}
@@ -0,0 +1,113 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Namespace is the namespace a project belongs to (a user or a group).
+type Namespace struct {
+	ID       int    `json:"id"`
+	Kind     string `json:"kind"` // "user" or "group"
+	FullPath string `json:"full_path"`
+}
+
+// Project holds the project fields glenv needs.
+type Project struct {
+	ID                int       `json:"id"`
+	PathWithNamespace string    `json:"path_with_namespace"`
+	WebURL            string    `json:"web_url"`
+	DefaultBranch     string    `json:"default_branch"`
+	Namespace         Namespace `json:"namespace"`
+}
+
+// Group holds the group fields glenv needs.
+type Group struct {
+	ID       int    `json:"id"`
+	FullPath string `json:"full_path"`
+	ParentID *int   `json:"parent_id"`
+}
+
+// maxGroupDepth bounds the parent walk in GroupChain (GitLab allows 20 levels).
+const maxGroupDepth = 20
+
+// GetProject fetches a project by ID or URL-encoded path.
+func (c *Client) GetProject(ctx context.Context, projectID string) (*Project, error) {
+	var p Project
+	if err := c.getJSON(ctx, "get project", "/api/v4/projects/"+url.PathEscape(projectID), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetGroup fetches a group by ID or URL-encoded path.
+func (c *Client) GetGroup(ctx context.Context, groupID string) (*Group, error) {
+	var g Group
+	q := url.Values{}
+	q.Set("with_projects", "false")
+	if err := c.getJSON(ctx, "get group", "/api/v4/groups/"+url.PathEscape(groupID)+"?"+q.Encode(), &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// GroupChain returns the groups a project inherits variables from, ordered
+// from the top-level group down to the project's own group. Projects in a
+// user namespace have no groups.
+func (c *Client) GroupChain(ctx context.Context, projectID string) ([]Group, error) {
+	p, err := c.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Namespace.Kind != "group" {
+		return nil, nil
+	}
+
+	var chain []Group
+	id := p.Namespace.ID
+	for range maxGroupDepth {
+		g, err := c.GetGroup(ctx, strconv.Itoa(id))
+		if err != nil {
+			return nil, err
+		}
+		chain = append([]Group{*g}, chain...)
+		if g.ParentID == nil {
+			return chain, nil
+		}
+		id = *g.ParentID
+	}
+	return nil, fmt.Errorf("gitlab: group chain: exceeded %d levels", maxGroupDepth)
+}
+
+// ListGroupVariables returns all CI/CD variables of a group, following pagination.
+func (c *Client) ListGroupVariables(ctx context.Context, groupID string) ([]Variable, error) {
+	path := fmt.Sprintf("/api/v4/groups/%s/variables", url.PathEscape(groupID))
+	vars, err := listPaged[Variable](ctx, c, "list group variables", path, nil, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return defaultScope(vars), nil
+}
+
+// ListInstanceVariables returns all instance-level CI/CD variables.
+// This endpoint requires administrator access; other tokens get ErrForbidden.
+func (c *Client) ListInstanceVariables(ctx context.Context) ([]Variable, error) {
+	vars, err := listPaged[Variable](ctx, c, "list instance variables", "/api/v4/admin/ci/variables", nil, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return defaultScope(vars), nil
+}
+
+// defaultScope sets EnvironmentScope to "*" where the API omitted it: instance
+// variables have no scope and group scopes are a paid feature.
+func defaultScope(vars []Variable) []Variable {
+	for i := range vars {
+		if vars[i].EnvironmentScope == "" {
+			vars[i].EnvironmentScope = WildcardScope
+		}
+	}
+	return vars
+}
@@ -0,0 +1,72 @@
+//nolint:errcheck // test file
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupChain(t *testing.T) {
+	parent := 1
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v4/projects/42":
+			json.NewEncoder(w).Encode(Project{ID: 42, Namespace: Namespace{ID: 2, Kind: "group", FullPath: "acme/backend"}})
+		case "/api/v4/groups/2":
+			json.NewEncoder(w).Encode(Group{ID: 2, FullPath: "acme/backend", ParentID: &parent})
+		case "/api/v4/groups/1":
+			json.NewEncoder(w).Encode(Group{ID: 1, FullPath: "acme"})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	chain, err := client.GroupChain(context.Background(), "42")
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+	assert.Equal(t, "acme", chain[0].FullPath)
+	assert.Equal(t, "acme/backend", chain[1].FullPath)
+}
+
+func TestGroupChain_UserNamespace(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Project{ID: 42, Namespace: Namespace{ID: 9, Kind: "user"}})
+	})
+
+	chain, err := client.GroupChain(context.Background(), "42")
+	require.NoError(t, err)
+	assert.Empty(t, chain)
+}
+
+func TestListGroupVariables_DefaultsScope(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v4/groups/acme%2Fbackend/variables", r.URL.RawPath)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]Variable{{Key: "A", Value: "1"}, {Key: "B", Value: "2", EnvironmentScope: "production"}})
+	})
+
+	vars, err := client.ListGroupVariables(context.Background(), "acme/backend")
+	require.NoError(t, err)
+	require.Len(t, vars, 2)
+	assert.Equal(t, "*", vars[0].EnvironmentScope)
+	assert.Equal(t, "production", vars[1].EnvironmentScope)
+}
+
+func TestListInstanceVariables_Forbidden(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v4/admin/ci/variables", r.URL.Path)
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	_, err := client.ListInstanceVariables(context.Background())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,80 @@
+package gitlab
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// redacted replaces secrets in log output.
+const redacted = "[REDACTED]"
+
+// discardLogger is used when ClientConfig.Logger is nil.
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// LogValue implements slog.LogValuer so that logging a variable never
+// writes its value.
+func (v Variable) LogValue() slog.Value {
+	return variableLogValue(v.Key, v.EnvironmentScope, v.VariableType, v.Protected, v.Masked)
+}
+
+// LogValue implements slog.LogValuer so that logging a request never
+// writes its value.
+func (r CreateRequest) LogValue() slog.Value {
+	return variableLogValue(r.Key, r.EnvironmentScope, r.VariableType, r.Protected, r.Masked)
+}
+
+func variableLogValue(key, scope, typ string, protected, masked bool) slog.Value {
+	return slog.GroupValue(
+		slog.String("key", key),
+		slog.String("value", redacted),
+		slog.String("environment_scope", scope),
+		slog.String("variable_type", typ),
+		slog.Bool("protected", protected),
+		slog.Bool("masked", masked),
+	)
+}
+
+// LogValue implements slog.LogValuer; the token is never logged.
+func (t PrivateToken) LogValue() slog.Value { return slog.StringValue(t.String()) }
+
+// LogValue implements slog.LogValuer; the token is never logged.
+func (t JobToken) LogValue() slog.Value { return slog.StringValue(t.String()) }
+
+// LogValue implements slog.LogValuer; the token is never logged.
+func (t BearerToken) LogValue() slog.Value { return slog.StringValue(t.String()) }
+
+// LogValue implements slog.LogValuer; the tokens are never logged.
+func (t OAuthToken) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("access_token", redacted),
+		slog.String("refresh_token", redacted),
+		slog.Time("expiry", t.Expiry()),
+	)
+}
+
+// requestAttrs describes req for a log record: method and path only, since
+// headers carry the token and bodies carry variable values.
+func requestAttrs(req *http.Request, attempt int) []any {
+	return []any{
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.Int("attempt", attempt+1),
+	}
+}
+
+func logRateChange(logger *slog.Logger, old, updated LimiterState) {
+	attrs := []any{slog.Float64("from", old.Rate), slog.Float64("to", updated.Rate)}
+	if updated.Limit > 0 {
+		attrs = append(attrs, slog.Int("remaining", updated.Remaining), slog.Int("limit", updated.Limit),
+			slog.Time("reset", updated.Reset))
+	} else {
+		attrs = append(attrs, slog.String("reason", "throttled by GitLab"))
+	}
+	logger.Info("gitlab: rate limit adjusted", attrs...)
+}
+
+func (c *Client) logRetry(req *http.Request, attempt int, reason string, backoff time.Duration) {
+	c.log.Warn("gitlab: retrying request",
+		append(requestAttrs(req, attempt), slog.String("reason", reason), slog.Duration("backoff", backoff))...)
+}
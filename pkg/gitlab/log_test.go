@@ -0,0 +1,106 @@
+//nolint:errcheck // test file
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDo_LogsRequestsAndRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var req CreateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Variable{Key: req.Key, Value: req.Value})
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewClient(ClientConfig{BaseURL: srv.URL, Token: "glpat-supersecret", RequestsPerSecond: 100,
+		RetryInitialBackoff: time.Millisecond, Logger: logger})
+
+	_, err := client.CreateVariable(context.Background(), "1", CreateRequest{Key: "API_KEY", Value: "s3cret-value"})
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.NotContains(t, out, "glpat-supersecret")
+	assert.NotContains(t, out, "s3cret-value")
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var rec map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		records = append(records, rec)
+	}
+	require.Len(t, records, 3, "request, retry, request")
+	assert.Equal(t, "gitlab: request", records[0]["msg"])
+	assert.Equal(t, "POST", records[0]["method"])
+	assert.Equal(t, "/api/v4/projects/1/variables", records[0]["path"])
+	assert.Equal(t, float64(http.StatusBadGateway), records[0]["status"])
+	assert.Contains(t, records[0], "latency")
+	assert.Contains(t, records[0], "wait")
+	assert.Equal(t, "gitlab: retrying request", records[1]["msg"])
+	assert.Equal(t, "server error 502", records[1]["reason"])
+	assert.Equal(t, float64(2), records[2]["attempt"])
+	assert.Equal(t, float64(http.StatusCreated), records[2]["status"])
+}
+
+func TestLogValue_RedactsValues(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	logger.Info("x",
+		slog.Any("var", Variable{Key: "DB_PASSWORD", Value: "hunter2", Masked: true}),
+		slog.Any("auth", JobToken("job-secret")),
+		slog.Any("oauth", OAuthToken{AccessToken: "access-secret", RefreshToken: "refresh-secret"}),
+	)
+
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "job-secret", "access-secret", "refresh-secret"} {
+		assert.NotContains(t, out, secret)
+	}
+	assert.Contains(t, out, "var.key=DB_PASSWORD")
+	assert.Contains(t, out, "var.value="+redacted)
+}
+
+func TestDo_LogsRateChanges(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range rateHeaders(2000, 10, time.Now().Add(10*time.Second)) {
+			w.Header()[k] = v
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	var called bool
+	client := NewClient(ClientConfig{BaseURL: srv.URL, Token: "t", RequestsPerSecond: 100, Logger: logger,
+		OnRateChange: func(_, _ LimiterState) { called = true }})
+
+	_, err := client.ListVariables(context.Background(), "1", ListOptions{})
+	require.NoError(t, err)
+
+	var rec map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rec), buf.String())
+	assert.Equal(t, "gitlab: rate limit adjusted", rec["msg"])
+	assert.Equal(t, float64(100), rec["from"])
+	assert.Equal(t, float64(10), rec["remaining"])
+	assert.True(t, called, "OnRateChange is still called")
+}
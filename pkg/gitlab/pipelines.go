@@ -0,0 +1,93 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Pipeline holds the pipeline fields glenv needs.
+type Pipeline struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+	Ref    string `json:"ref"`
+	SHA    string `json:"sha"`
+	WebURL string `json:"web_url"`
+}
+
+// PipelineVariable is a variable passed to a single pipeline run. It only
+// exists for that pipeline and is never stored as a project variable.
+type PipelineVariable struct {
+	Key          string `json:"key"`
+	Value        string `json:"value"`
+	VariableType string `json:"variable_type,omitempty"` // "env_var" or "file"
+}
+
+// Finished reports whether the pipeline reached a state it will not leave on
+// its own. "manual" counts as finished: it waits for a person to act, which
+// callers should report as such rather than as a failure.
+func (p *Pipeline) Finished() bool {
+	switch p.Status {
+	case "success", "failed", "canceled", "skipped", "manual":
+		return true
+	}
+	return false
+}
+
+// CreatePipeline starts a pipeline for ref with the given pipeline variables.
+// It is not retried after a network error or 5xx response: the pipeline may
+// have been created already, and a second one would run the jobs twice.
+func (c *Client) CreatePipeline(ctx context.Context, projectID, ref string, vars []PipelineVariable) (*Pipeline, error) {
+	body := struct {
+		Ref       string             `json:"ref"`
+		Variables []PipelineVariable `json:"variables,omitempty"`
+	}{Ref: ref, Variables: vars}
+
+	var p Pipeline
+	path := fmt.Sprintf("/api/v4/projects/%s/pipeline", url.PathEscape(projectID))
+	if err := c.postJSON(ctx, "create pipeline", path, body, &p, sendOptions{once: true}); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetPipeline fetches a single pipeline.
+func (c *Client) GetPipeline(ctx context.Context, projectID string, id int) (*Pipeline, error) {
+	var p Pipeline
+	path := fmt.Sprintf("/api/v4/projects/%s/pipelines/%d", url.PathEscape(projectID), id)
+	if err := c.getJSON(ctx, "get pipeline", path, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// WaitPipeline polls a pipeline every interval until it finishes or ctx is
+// done. onStatus, if non-nil, is called whenever the status changes.
+func (c *Client) WaitPipeline(ctx context.Context, projectID string, id int, interval time.Duration, onStatus func(*Pipeline)) (*Pipeline, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := ""
+	for {
+		p, err := c.GetPipeline(ctx, projectID, id)
+		if err != nil {
+			return nil, err
+		}
+		if p.Status != last {
+			last = p.Status
+			if onStatus != nil {
+				onStatus(p)
+			}
+		}
+		if p.Finished() {
+			return p, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return p, fmt.Errorf("gitlab: wait pipeline %d: %w", id, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,107 @@
+//nolint:errcheck // test file
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePipeline(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v4/projects/42/pipeline", r.URL.Path)
+		var body struct {
+			Ref       string             `json:"ref"`
+			Variables []PipelineVariable `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "main", body.Ref)
+		assert.Equal(t, []PipelineVariable{{Key: "GLENV_ENVIRONMENT", Value: "production"}}, body.Variables)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Pipeline{ID: 7, Status: "created", Ref: "main"})
+	})
+
+	p, err := client.CreatePipeline(context.Background(), "42", "main",
+		[]PipelineVariable{{Key: "GLENV_ENVIRONMENT", Value: "production"}})
+	require.NoError(t, err)
+	assert.Equal(t, 7, p.ID)
+	assert.Equal(t, "created", p.Status)
+}
+
+func TestCreatePipeline_BadRef(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":{"base":["Reference not found"]}}`))
+	})
+
+	_, err := client.CreatePipeline(context.Background(), "42", "nope", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Reference not found")
+}
+
+func TestCreatePipeline_NotRetried(t *testing.T) {
+	var calls atomic.Int32
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	_, err := client.CreatePipeline(context.Background(), "42", "main", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not retried")
+	assert.Equal(t, int32(1), calls.Load(), "a retry could start a second pipeline")
+}
+
+func TestCreatePipeline_NetworkErrorNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		conn.Close()
+	})
+
+	_, err := client.CreatePipeline(context.Background(), "42", "main", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "may have been applied")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestWaitPipeline(t *testing.T) {
+	statuses := []string{"pending", "running", "running", "success"}
+	var calls atomic.Int32
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v4/projects/42/pipelines/7", r.URL.Path)
+		n := int(calls.Add(1)) - 1
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Pipeline{ID: 7, Status: statuses[min(n, len(statuses)-1)]})
+	})
+
+	var seen []string
+	p, err := client.WaitPipeline(context.Background(), "42", 7, time.Millisecond, func(p *Pipeline) {
+		seen = append(seen, p.Status)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "success", p.Status)
+	assert.Equal(t, []string{"pending", "running", "success"}, seen)
+}
+
+func TestWaitPipeline_ContextDone(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Pipeline{ID: 7, Status: "running"})
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.WaitPipeline(ctx, "42", 7, 5*time.Millisecond, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
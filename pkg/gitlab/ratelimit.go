@@ -0,0 +1,130 @@
+package gitlab
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Adaptive rate limiting tunables.
+const (
+	// minRequestsPerSecond is the slowest rate the limiter adapts down to.
+	minRequestsPerSecond = 0.5
+	// rateSafetyFactor leaves headroom below the rate that would exactly
+	// use up the remaining quota by the reset time.
+	rateSafetyFactor = 0.8
+	// headroomFraction of the quota remaining restores the configured rate.
+	headroomFraction = 0.5
+	// minRateChange is the relative change below which the rate is kept, to
+	// avoid churning the limiter on every response.
+	minRateChange = 0.1
+)
+
+// LimiterState is a snapshot of the client's adaptive rate limiter.
+type LimiterState struct {
+	// Rate is the current request rate in requests per second and MaxRate
+	// the configured one.
+	Rate    float64
+	MaxRate float64
+	// Limit, Remaining and Reset are the last RateLimit-* headers seen;
+	// Limit is 0 if GitLab sent none.
+	Limit       int
+	Remaining   int
+	Reset       time.Time
+	Adjustments int // number of rate changes
+}
+
+// Throttled reports whether the limiter runs below the configured rate.
+func (s LimiterState) Throttled() bool {
+	return s.Rate < s.MaxRate
+}
+
+// adaptiveLimiter wraps rate.Limiter and tunes its rate from GitLab's
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers: it
+// slows down so the remaining quota lasts until the reset and returns to
+// the configured rate once there is headroom again.
+type adaptiveLimiter struct {
+	*rate.Limiter
+
+	mu       sync.Mutex
+	state    LimiterState
+	onChange func(old, updated LimiterState)
+	now      func() time.Time
+}
+
+func newAdaptiveLimiter(rps float64, burst int, onChange func(old, updated LimiterState)) *adaptiveLimiter {
+	return &adaptiveLimiter{
+		Limiter:  rate.NewLimiter(rate.Limit(rps), burst),
+		state:    LimiterState{Rate: rps, MaxRate: rps},
+		onChange: onChange,
+		now:      time.Now,
+	}
+}
+
+// observe adapts the rate to the RateLimit-* headers of a response.
+func (l *adaptiveLimiter) observe(h http.Header) {
+	remaining, err := strconv.Atoi(h.Get("RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	limit, _ := strconv.Atoi(h.Get("RateLimit-Limit"))
+	resetUnix, err := strconv.ParseInt(h.Get("RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	reset := time.Unix(resetUnix, 0)
+	l.state.Limit, l.state.Remaining, l.state.Reset = limit, remaining, reset
+
+	target := l.state.MaxRate
+	if limit <= 0 || float64(remaining) < headroomFraction*float64(limit) {
+		window := max(reset.Sub(now).Seconds(), 1)
+		target = min(l.state.MaxRate, max(minRequestsPerSecond, rateSafetyFactor*float64(remaining)/window))
+	}
+
+	current := l.state.Rate
+	if target != l.state.MaxRate && abs(target-current)/current < minRateChange {
+		return
+	}
+	l.setRateLocked(target)
+}
+
+// throttle halves the rate after a 429 response.
+func (l *adaptiveLimiter) throttle() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.setRateLocked(max(minRequestsPerSecond, l.state.Rate/2))
+}
+
+// setRateLocked changes the rate and reports the change. l.mu must be held.
+func (l *adaptiveLimiter) setRateLocked(target float64) {
+	if target == l.state.Rate {
+		return
+	}
+	old := l.state
+	l.state.Rate = target
+	l.state.Adjustments++
+	l.SetLimit(rate.Limit(target))
+	if l.onChange != nil {
+		l.onChange(old, l.state)
+	}
+}
+
+func (l *adaptiveLimiter) snapshot() LimiterState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
@@ -0,0 +1,89 @@
+//nolint:errcheck // test file
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rateHeaders(limit, remaining int, reset time.Time) http.Header {
+	h := http.Header{}
+	h.Set("RateLimit-Limit", strconv.Itoa(limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+	h.Set("RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	return h
+}
+
+func TestAdaptiveLimiter_Observe(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	var changes []LimiterState
+	l := newAdaptiveLimiter(10, 10, func(_, updated LimiterState) { changes = append(changes, updated) })
+	l.now = func() time.Time { return now }
+
+	// Plenty of quota left: keep the configured rate.
+	l.observe(rateHeaders(600, 550, now.Add(time.Minute)))
+	assert.Equal(t, 10.0, l.snapshot().Rate)
+	assert.Empty(t, changes)
+
+	// Running low: 100 requests for 60s → 0.8*100/60 req/s.
+	l.observe(rateHeaders(600, 100, now.Add(time.Minute)))
+	state := l.snapshot()
+	assert.InDelta(t, 0.8*100/60, state.Rate, 0.001)
+	assert.True(t, state.Throttled())
+	assert.Equal(t, 100, state.Remaining)
+	assert.Equal(t, 600, state.Limit)
+	require.Len(t, changes, 1)
+
+	// A small change is ignored.
+	l.observe(rateHeaders(600, 98, now.Add(time.Minute)))
+	assert.Len(t, changes, 1)
+
+	// Headroom is back after the reset: speed up to the configured rate.
+	l.observe(rateHeaders(600, 599, now.Add(time.Minute)))
+	assert.Equal(t, 10.0, l.snapshot().Rate)
+	assert.Len(t, changes, 2)
+	assert.Equal(t, 2, l.snapshot().Adjustments)
+}
+
+func TestAdaptiveLimiter_Floor(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := newAdaptiveLimiter(10, 10, nil)
+	l.now = func() time.Time { return now }
+
+	l.observe(rateHeaders(600, 0, now.Add(time.Minute)))
+	assert.Equal(t, minRequestsPerSecond, l.snapshot().Rate)
+}
+
+func TestAdaptiveLimiter_IgnoresMissingHeaders(t *testing.T) {
+	l := newAdaptiveLimiter(10, 10, nil)
+	l.observe(http.Header{})
+	assert.Equal(t, LimiterState{Rate: 10, MaxRate: 10}, l.snapshot())
+}
+
+func TestAdaptiveLimiter_Throttle(t *testing.T) {
+	l := newAdaptiveLimiter(8, 8, nil)
+	l.throttle()
+	assert.Equal(t, 4.0, l.snapshot().Rate)
+}
+
+func TestDo_AdaptsToRateLimitHeaders(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range rateHeaders(2000, 10, time.Now().Add(10*time.Second)) {
+			w.Header()[k] = v
+		}
+		w.Write([]byte(`[]`))
+	})
+
+	_, err := client.ListVariables(context.Background(), "1", ListOptions{})
+	require.NoError(t, err)
+	state := client.LimiterState()
+	assert.Equal(t, 100.0, state.MaxRate)
+	assert.Less(t, state.Rate, 1.0)
+	assert.Equal(t, 10, state.Remaining)
+}
@@ -0,0 +1,64 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrRetryBudgetExhausted is returned once the client used up its retry budget.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// retryBudget counts the retries left for all requests of a client.
+type retryBudget struct {
+	limit int64 // 0 means unlimited
+	used  atomic.Int64
+}
+
+func newRetryBudget(limit int) *retryBudget {
+	return &retryBudget{limit: int64(limit)}
+}
+
+// take consumes one retry and reports whether it was available.
+func (b *retryBudget) take() bool {
+	n := b.used.Add(1)
+	return b.limit <= 0 || n <= b.limit
+}
+
+func (b *retryBudget) exhausted(last error) error {
+	return fmt.Errorf("gitlab: %w after %d retries: %w", ErrRetryBudgetExhausted, b.limit, last)
+}
+
+// isAlreadyTaken reports whether a create failed because the key already
+// exists in the scope (HTTP 400 "has already been taken").
+func isAlreadyTaken(status int, body string) bool {
+	return status == http.StatusBadRequest && strings.Contains(body, "has already been taken")
+}
+
+// matchesRequest reports whether v is the variable r would create.
+func matchesRequest(v *Variable, r CreateRequest) bool {
+	scope := r.EnvironmentScope
+	if scope == "" {
+		scope = "*"
+	}
+	varType := r.VariableType
+	if varType == "" {
+		varType = "env_var"
+	}
+	return v.Value == r.Value && v.VariableType == varType && v.EnvironmentScope == scope &&
+		v.Protected == r.Protected && v.Masked == r.Masked && v.Raw == r.Raw
+}
+
+// verifyCreated handles a create that was resent after an uncertain failure
+// and then rejected as a duplicate: the first attempt most likely succeeded.
+// It returns the existing variable if it matches r.
+func (c *Client) verifyCreated(ctx context.Context, projectID string, r CreateRequest) (*Variable, bool) {
+	existing, err := c.GetVariable(ctx, projectID, r.Key, r.EnvironmentScope)
+	if err != nil || !matchesRequest(existing, r) {
+		return nil, false
+	}
+	return existing, true
+}
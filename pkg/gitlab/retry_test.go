@@ -0,0 +1,126 @@
+//nolint:errcheck // test file
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lostResponseServer applies the first create but answers it with 502, as if
+// the response was lost, and rejects the resent create as a duplicate.
+func lostResponseServer(t *testing.T, stored Variable) (*Client, *atomic.Int32, *atomic.Int32) {
+	t.Helper()
+	var posts, gets atomic.Int32
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			if posts.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":{"key":["(API_KEY) has already been taken"]}}`))
+		case http.MethodGet:
+			gets.Add(1)
+			json.NewEncoder(w).Encode(stored)
+		}
+	})
+	return client, &posts, &gets
+}
+
+func TestCreateVariable_ResentDuplicateMatches(t *testing.T) {
+	client, posts, gets := lostResponseServer(t, Variable{Key: "API_KEY", Value: "secret", VariableType: "env_var",
+		EnvironmentScope: "production", Masked: true})
+
+	v, err := client.CreateVariable(context.Background(), "1",
+		CreateRequest{Key: "API_KEY", Value: "secret", VariableType: "env_var", EnvironmentScope: "production", Masked: true})
+	require.NoError(t, err)
+	assert.Equal(t, "secret", v.Value)
+	assert.Equal(t, int32(2), posts.Load())
+	assert.Equal(t, int32(1), gets.Load())
+}
+
+func TestCreateVariable_ResentDuplicateDiffers(t *testing.T) {
+	client, _, _ := lostResponseServer(t, Variable{Key: "API_KEY", Value: "other", VariableType: "env_var", EnvironmentScope: "*"})
+
+	_, err := client.CreateVariable(context.Background(), "1", CreateRequest{Key: "API_KEY", Value: "secret"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has already been taken")
+}
+
+func TestCreateVariable_DuplicateWithoutRetry(t *testing.T) {
+	var gets atomic.Int32
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets.Add(1)
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":{"key":["(API_KEY) has already been taken"]}}`))
+	})
+
+	_, err := client.CreateVariable(context.Background(), "1", CreateRequest{Key: "API_KEY", Value: "secret"})
+	require.Error(t, err)
+	assert.Zero(t, gets.Load(), "a duplicate on the first attempt is a real conflict")
+}
+
+func TestDeleteVariable_ResentNotFound(t *testing.T) {
+	var calls atomic.Int32
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	require.NoError(t, client.DeleteVariable(context.Background(), "1", "API_KEY", ""))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestDo_RetryBudget(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	client := NewClient(ClientConfig{BaseURL: srv.URL, Token: "t", RequestsPerSecond: 100,
+		RetryMax: 3, RetryInitialBackoff: time.Millisecond, RetryBudget: 2})
+
+	_, err := client.ListVariables(context.Background(), "1", ListOptions{})
+	assert.True(t, errors.Is(err, ErrRetryBudgetExhausted))
+	assert.Equal(t, int32(3), calls.Load(), "two retries, then the budget is used up")
+
+	_, err = client.ListVariables(context.Background(), "1", ListOptions{})
+	assert.True(t, errors.Is(err, ErrRetryBudgetExhausted))
+	assert.Equal(t, int32(4), calls.Load(), "later requests fail without retrying")
+}
+
+func TestDo_RateLimitedRetriesSkipBudget(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1)%2 == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+	client := NewClient(ClientConfig{BaseURL: srv.URL, Token: "t", RequestsPerSecond: 100,
+		RetryMax: 3, RetryInitialBackoff: time.Millisecond, RetryBudget: 1})
+
+	for range 3 {
+		_, err := client.ListVariables(context.Background(), "1", ListOptions{})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(6), calls.Load())
+}
@@ -0,0 +1,98 @@
+package gitlab
+
+import (
+	"sort"
+	"strings"
+)
+
+// WildcardScope is the environment scope that applies to every environment.
+const WildcardScope = "*"
+
+// ScopeMatches reports whether a variable with the given environment scope is
+// visible to jobs running in environment, following GitLab's rules:
+//   - "*" matches every environment
+//   - a scope without "*" matches only the environment with that exact name
+//   - any other "*" matches any sequence of characters, including "/",
+//     so "review/*" matches "review/feature-x" and "prod-*" matches "prod-eu"
+func ScopeMatches(scope, environment string) bool {
+	if scope == WildcardScope || scope == environment {
+		return true
+	}
+	if !strings.Contains(scope, "*") {
+		return false
+	}
+	return globMatch(scope, environment)
+}
+
+// globMatch matches s against pattern where "*" is the only metacharacter.
+func globMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := len(parts) - 1
+	for _, part := range parts[1:last] {
+		idx := strings.Index(s, part)
+		if idx < 0 {
+			return false
+		}
+		s = s[idx+len(part):]
+	}
+	return strings.HasSuffix(s, parts[last])
+}
+
+// scopeRank orders matching scopes by specificity for environment:
+// an exact match ranks highest, then wildcard patterns, then "*".
+func scopeRank(scope, environment string) int {
+	switch scope {
+	case environment:
+		return 2
+	case WildcardScope:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// MoreSpecific reports whether scope a takes precedence over scope b for
+// jobs in environment. Both scopes are assumed to match environment.
+//
+// GitLab prefers an exact match over wildcard patterns and patterns over "*".
+// Between two matching patterns it picks the longer one, ordering by
+// LENGTH(environment_scope) with the wildcards counted ("r*v*e*/*-x" over
+// "review/*"). Patterns of equal length are ordered by name so the result is
+// deterministic.
+func MoreSpecific(a, b, environment string) bool {
+	ra, rb := scopeRank(a, environment), scopeRank(b, environment)
+	if ra != rb {
+		return ra > rb
+	}
+	if len(a) != len(b) {
+		return len(a) > len(b)
+	}
+	return a < b
+}
+
+// ResolveScope returns the variable each key resolves to for jobs running in
+// environment: of all variables whose scope matches, the most specific one
+// wins (see MoreSpecific). The result is sorted by key.
+func ResolveScope(vars []Variable, environment string) []Variable {
+	winners := make(map[string]Variable)
+	for _, v := range vars {
+		if !ScopeMatches(v.EnvironmentScope, environment) {
+			continue
+		}
+		cur, ok := winners[v.Key]
+		if !ok || MoreSpecific(v.EnvironmentScope, cur.EnvironmentScope, environment) {
+			winners[v.Key] = v
+		}
+	}
+
+	result := make([]Variable, 0, len(winners))
+	for _, v := range winners {
+		result = append(result, v)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
@@ -0,0 +1,65 @@
+package gitlab
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopeMatches(t *testing.T) {
+	tests := []struct {
+		scope, env string
+		want       bool
+	}{
+		{"*", "production", true},
+		{"production", "production", true},
+		{"production", "production-eu", false},
+		{"review/*", "review/feature-x", true},
+		{"review/*", "review/a/b", true},
+		{"review/*", "review", false},
+		{"prod-*", "prod-eu", true},
+		{"prod-*", "production", false},
+		{"*-eu", "prod-eu", true},
+		{"review/*/app", "review/x/app", true},
+		{"review/*/app", "review/x/api", false},
+		{"review/**", "review/x", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ScopeMatches(tt.scope, tt.env), "ScopeMatches(%q, %q)", tt.scope, tt.env)
+	}
+}
+
+func TestMoreSpecific(t *testing.T) {
+	env := "review/feature-x"
+	assert.True(t, MoreSpecific("review/feature-x", "review/*", env))
+	assert.True(t, MoreSpecific("review/*", "*", env))
+	assert.True(t, MoreSpecific("review/feature-*", "review/*", env))
+	assert.False(t, MoreSpecific("*", "review/*", env))
+	// Patterns rank by full length like GitLab, wildcards included.
+	assert.True(t, MoreSpecific("r*v*e*/*-x", "review/*", env))
+	assert.False(t, MoreSpecific("review/*", "r*v*e*/*-x", env))
+	assert.True(t, MoreSpecific("rev*ew/*x", "review/*", env))
+}
+
+func TestResolveScope(t *testing.T) {
+	vars := []Variable{
+		{Key: "DB_URL", Value: "global", EnvironmentScope: "*"},
+		{Key: "DB_URL", Value: "review", EnvironmentScope: "review/*"},
+		{Key: "DB_URL", Value: "prod", EnvironmentScope: "production"},
+		{Key: "API_URL", Value: "exact", EnvironmentScope: "review/feature-x"},
+		{Key: "API_URL", Value: "review", EnvironmentScope: "review/*"},
+		{Key: "ONLY_PROD", Value: "x", EnvironmentScope: "production"},
+	}
+
+	got := ResolveScope(vars, "review/feature-x")
+	require.Len(t, got, 2)
+	assert.Equal(t, "API_URL", got[0].Key)
+	assert.Equal(t, "exact", got[0].Value)
+	assert.Equal(t, "DB_URL", got[1].Key)
+	assert.Equal(t, "review", got[1].Value)
+
+	got = ResolveScope(vars, "staging")
+	require.Len(t, got, 1)
+	assert.Equal(t, "global", got[0].Value)
+}
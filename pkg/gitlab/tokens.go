@@ -0,0 +1,64 @@
+package gitlab
+
+import (
+	"context"
+	"time"
+)
+
+// TokenInfo describes the personal, project or group access token in use.
+type TokenInfo struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	Active     bool       `json:"active"`
+	Revoked    bool       `json:"revoked"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  *Date      `json:"expires_at"` // nil if the token never expires
+	UserID     int        `json:"user_id"`
+}
+
+// User holds the user fields glenv needs.
+type User struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// Date is a calendar date in GitLab's "2006-01-02" format.
+type Date struct {
+	time.Time
+}
+
+// UnmarshalJSON parses a "YYYY-MM-DD" string.
+func (d *Date) UnmarshalJSON(b []byte) error {
+	t, err := time.Parse(`"2006-01-02"`, string(b))
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+func (d Date) String() string {
+	return d.Format(time.DateOnly)
+}
+
+// GetTokenInfo fetches the access token the client authenticates with. Only
+// personal, project and group access tokens can be inspected this way.
+func (c *Client) GetTokenInfo(ctx context.Context) (*TokenInfo, error) {
+	var t TokenInfo
+	if err := c.getJSON(ctx, "get token info", "/api/v4/personal_access_tokens/self", &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CurrentUser fetches the user the client authenticates as.
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	var u User
+	if err := c.getJSON(ctx, "get current user", "/api/v4/user", &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
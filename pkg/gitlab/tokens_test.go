@@ -0,0 +1,62 @@
+//nolint:errcheck // test file
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTokenInfo(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v4/personal_access_tokens/self", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":3,"name":"glenv","scopes":["api","read_api"],"active":true,"revoked":false,
+			"created_at":"2026-01-02T10:00:00.000Z","last_used_at":null,"expires_at":"2026-12-31","user_id":9}`))
+	})
+
+	info, err := client.GetTokenInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "glenv", info.Name)
+	assert.Equal(t, []string{"api", "read_api"}, info.Scopes)
+	assert.True(t, info.Active)
+	assert.Nil(t, info.LastUsedAt)
+	require.NotNil(t, info.ExpiresAt)
+	assert.Equal(t, "2026-12-31", info.ExpiresAt.String())
+}
+
+func TestGetTokenInfo_NeverExpires(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":3,"name":"glenv","scopes":["api"],"active":true,"created_at":"2026-01-02T10:00:00Z","expires_at":null}`))
+	})
+
+	info, err := client.GetTokenInfo(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, info.ExpiresAt)
+}
+
+func TestGetTokenInfo_NotFound(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	_, err := client.GetTokenInfo(context.Background())
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestCurrentUser(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v4/user", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":9,"username":"jdoe","name":"J Doe"}`))
+	})
+
+	u, err := client.CurrentUser(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "jdoe", u.Username)
+}
@@ -0,0 +1,84 @@
+package gitlab
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// defaultTimeout is the overall request timeout when HTTPConfig.Timeout is unset.
+const defaultTimeout = 30 * time.Second
+
+// HTTPConfig holds transport settings for instances behind an internal CA,
+// mutual TLS or a proxy.
+type HTTPConfig struct {
+	CACertFile     string // PEM bundle trusted in addition to the system roots
+	ClientCertFile string // PEM client certificate for mutual TLS
+	ClientKeyFile  string // PEM private key of ClientCertFile
+	// InsecureSkipVerify disables certificate verification. Only for testing.
+	InsecureSkipVerify  bool
+	ProxyURL            string // overrides HTTPS_PROXY/HTTP_PROXY/NO_PROXY
+	Timeout             time.Duration
+	DialTimeout         time.Duration
+	TLSHandshakeTimeout time.Duration
+}
+
+// NewHTTPClient builds an http.Client for ClientConfig.HTTPClient from cfg.
+// Zero fields keep the defaults of http.DefaultTransport.
+func NewHTTPClient(cfg HTTPConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CACertFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(cfg.CACertFile) //nolint:gosec // G304: path comes from trusted config
+		if err != nil {
+			return nil, fmt.Errorf("gitlab: read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("gitlab: CA bundle %s contains no PEM certificates", cfg.CACertFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	switch {
+	case cfg.ClientCertFile != "" && cfg.ClientKeyFile != "":
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("gitlab: load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	case cfg.ClientCertFile != "" || cfg.ClientKeyFile != "":
+		return nil, errors.New("gitlab: client certificate and key must be set together")
+	}
+	tlsCfg.InsecureSkipVerify = cfg.InsecureSkipVerify //nolint:gosec // G402: explicit opt-in, warned about by the CLI
+	transport.TLSClientConfig = tlsCfg
+
+	if cfg.ProxyURL != "" {
+		proxy, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("gitlab: invalid proxy URL %q", cfg.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if cfg.DialTimeout > 0 {
+		transport.DialContext = (&net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	}
+	if cfg.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
@@ -0,0 +1,110 @@
+//nolint:errcheck // test file
+package gitlab
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePEM writes the certificate and private key of cert to PEM files.
+func writePEM(t *testing.T, cert tls.Certificate) (certFile, keyFile string) {
+	t.Helper()
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key})
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	return certFile, keyFile
+}
+
+func TestNewHTTPClient_CACert(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	caFile, _ := writePEM(t, ts.TLS.Certificates[0])
+
+	plain, err := NewHTTPClient(HTTPConfig{})
+	require.NoError(t, err)
+	_, err = plain.Get(ts.URL)
+	assert.Error(t, err, "the test CA is not trusted by default")
+
+	client, err := NewHTTPClient(HTTPConfig{CACertFile: caFile})
+	require.NoError(t, err)
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestNewHTTPClient_InsecureSkipVerify(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	client, err := NewHTTPClient(HTTPConfig{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestNewHTTPClient_ClientCert(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert, MinVersion: tls.VersionTLS12}
+	ts.StartTLS()
+	defer ts.Close()
+	certFile, keyFile := writePEM(t, ts.TLS.Certificates[0])
+
+	client, err := NewHTTPClient(HTTPConfig{CACertFile: certFile, ClientCertFile: certFile, ClientKeyFile: keyFile})
+	require.NoError(t, err)
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = NewHTTPClient(HTTPConfig{ClientCertFile: certFile})
+	assert.Error(t, err, "certificate without key")
+}
+
+func TestNewHTTPClient_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	client, err := NewHTTPClient(HTTPConfig{ProxyURL: proxy.URL, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	resp, err := client.Get("http://gitlab.internal.example/api/v4/version")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "http://gitlab.internal.example/api/v4/version", proxied)
+	assert.Equal(t, 5*time.Second, client.Timeout)
+
+	_, err = NewHTTPClient(HTTPConfig{ProxyURL: "::bad"})
+	assert.Error(t, err)
+}
+
+func TestNewHTTPClient_BadCABundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, []byte("not a certificate"), 0o600))
+
+	_, err := NewHTTPClient(HTTPConfig{CACertFile: path})
+	assert.ErrorContains(t, err, "no PEM certificates")
+}
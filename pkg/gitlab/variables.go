@@ -4,11 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"strconv"
+)
+
+// Sentinel errors returned (wrapped) for well-known response statuses.
+var (
+	// ErrNotFound is returned when the requested resource does not exist.
+	ErrNotFound = errors.New("gitlab: not found")
+	// ErrForbidden is returned when the token lacks permission for the request.
+	ErrForbidden = errors.New("gitlab: forbidden")
 )
 
 // readErrorBody reads up to 512 bytes from the response body for error diagnostics.
@@ -40,20 +48,38 @@ type Variable struct {
 // Filtering rules:
 //   - empty scope: return all variables unfiltered
 //   - scope == "*": return only variables with EnvironmentScope == "*"
-//   - specific scope: return variables with EnvironmentScope == scope OR EnvironmentScope == "*"
+//   - wildcard scope (e.g. "review/*"): return variables with EnvironmentScope == scope OR "*"
+//   - environment name: return every variable visible to that environment per
+//     ScopeMatches — the exact scope, matching wildcard patterns and "*"
 func FilterByScope(vars []Variable, scope string) []Variable {
 	if scope == "" {
 		return vars
 	}
 	result := make([]Variable, 0, len(vars))
 	for _, v := range vars {
-		if v.EnvironmentScope == scope || (scope != "*" && v.EnvironmentScope == "*") {
+		if scopeVisible(v.EnvironmentScope, scope) {
 			result = append(result, v)
 		}
 	}
 	return result
 }
 
+// scopeVisible implements the FilterByScope rules for a single variable scope.
+func scopeVisible(varScope, scope string) bool {
+	switch {
+	case varScope == scope:
+		return true
+	case scope == WildcardScope:
+		return false
+	case IsWildcardScope(scope):
+		// A wildcard target is a scope, not an environment name: only the
+		// global scope applies to it as well.
+		return varScope == WildcardScope
+	default:
+		return ScopeMatches(varScope, scope)
+	}
+}
+
 // CreateRequest is the payload for creating or updating a variable.
 type CreateRequest struct {
 	Key              string `json:"key"`
@@ -74,63 +100,33 @@ type ListOptions struct {
 
 // ListVariables returns all variables for the given project, following pagination.
 func (c *Client) ListVariables(ctx context.Context, projectID string, opts ListOptions) ([]Variable, error) {
-	perPage := opts.PerPage
-	if perPage <= 0 {
-		perPage = 100
+	q := url.Values{}
+	if opts.EnvironmentScope != "" {
+		q.Set("filter[environment_scope]", opts.EnvironmentScope)
 	}
+	path := fmt.Sprintf("/api/v4/projects/%s/variables", url.PathEscape(projectID))
+	return listPaged[Variable](ctx, c, "list variables", path, q, opts.Page, opts.PerPage)
+}
 
-	var all []Variable
-	page := 1
-	if opts.Page > 0 {
-		page = opts.Page
+// GetVariable fetches a single CI/CD variable identified by key and envScope.
+// envScope is optional; pass "" to omit the filter. A missing variable yields
+// an error wrapping ErrNotFound.
+func (c *Client) GetVariable(ctx context.Context, projectID, key, envScope string) (*Variable, error) {
+	q := url.Values{}
+	if envScope != "" {
+		q.Set("filter[environment_scope]", envScope)
 	}
 
-	const maxPages = 1000
-	for pageNum := 0; pageNum < maxPages; pageNum++ {
-		q := url.Values{}
-		q.Set("per_page", strconv.Itoa(perPage))
-		q.Set("page", strconv.Itoa(page))
-		if opts.EnvironmentScope != "" {
-			q.Set("filter[environment_scope]", opts.EnvironmentScope)
-		}
-
-		apiURL := fmt.Sprintf("%s/api/v4/projects/%s/variables?%s", c.cfg.BaseURL, url.PathEscape(projectID), q.Encode())
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
-		if err != nil {
-			return nil, fmt.Errorf("gitlab: list variables: build request: %w", err)
-		}
-
-		resp, err := c.Do(ctx, req)
-		if err != nil {
-			return nil, fmt.Errorf("gitlab: list variables: %w", err)
-		}
-
-		if resp.StatusCode != http.StatusOK {
-			msg := readErrorBody(resp)
-			_ = resp.Body.Close()
-			return nil, fmt.Errorf("gitlab: list variables: unexpected status %d%s", resp.StatusCode, msg)
-		}
-
-		var pageVars []Variable
-		decodeErr := json.NewDecoder(resp.Body).Decode(&pageVars)
-		_ = resp.Body.Close()
-		if decodeErr != nil {
-			return nil, fmt.Errorf("gitlab: list variables: decode: %w", decodeErr)
-		}
-		all = append(all, pageVars...)
-
-		nextPage := resp.Header.Get("X-Next-Page")
-		if nextPage == "" || nextPage == "0" {
-			return all, nil
-		}
-		n, err := strconv.Atoi(nextPage)
-		if err != nil || n <= page {
-			return all, nil
-		}
-		page = n
+	path := fmt.Sprintf("/api/v4/projects/%s/variables/%s", url.PathEscape(projectID), url.PathEscape(key))
+	if len(q) > 0 {
+		path += "?" + q.Encode()
 	}
 
-	return nil, fmt.Errorf("gitlab: list variables: exceeded %d pages; possible pagination loop", maxPages)
+	var v Variable
+	if err := c.getJSON(ctx, "get variable "+key, path, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
 }
 
 // CreateVariable creates a new CI/CD variable for the given project.
@@ -147,14 +143,23 @@ func (c *Client) CreateVariable(ctx context.Context, projectID string, r CreateR
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.Do(ctx, req)
+	var resent bool
+	resp, err := c.do(ctx, req, sendOptions{resent: &resent})
 	if err != nil {
 		return nil, fmt.Errorf("gitlab: create variable: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("gitlab: create variable: unexpected status %d%s", resp.StatusCode, readErrorBody(resp))
+		msg := readErrorBody(resp)
+		// A resent create rejected as a duplicate usually means the first
+		// attempt succeeded and only its response was lost.
+		if resent && isAlreadyTaken(resp.StatusCode, msg) {
+			if v, ok := c.verifyCreated(ctx, projectID, r); ok {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("gitlab: create variable: unexpected status %d%s", resp.StatusCode, msg)
 	}
 
 	var v Variable
@@ -222,12 +227,17 @@ func (c *Client) DeleteVariable(ctx context.Context, projectID, key, envScope st
 		return fmt.Errorf("gitlab: delete variable: build request: %w", err)
 	}
 
-	resp, err := c.Do(ctx, req)
+	var resent bool
+	resp, err := c.do(ctx, req, sendOptions{resent: &resent})
 	if err != nil {
 		return fmt.Errorf("gitlab: delete variable: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	// A resent delete that finds nothing was applied by an earlier attempt.
+	if resent && resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
 	if resp.StatusCode != http.StatusNoContent {
 		return fmt.Errorf("gitlab: delete variable: unexpected status %d%s", resp.StatusCode, readErrorBody(resp))
 	}
@@ -195,6 +195,44 @@ func TestDeleteVariable_WithScope(t *testing.T) {
 	assert.Equal(t, "production", receivedScope)
 }
 
+func TestGetVariable(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/v4/projects/7/variables/MY_VAR", r.URL.Path)
+		assert.Equal(t, "production", r.URL.Query().Get("filter[environment_scope]"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Variable{Key: "MY_VAR", Value: "v", EnvironmentScope: "production"})
+	})
+
+	v, err := client.GetVariable(context.Background(), "7", "MY_VAR", "production")
+	require.NoError(t, err)
+	assert.Equal(t, "v", v.Value)
+	assert.Equal(t, "production", v.EnvironmentScope)
+}
+
+func TestGetVariable_NotFound(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"404 Variable Not Found"}`)
+	})
+
+	_, err := client.GetVariable(context.Background(), "7", "MISSING", "")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Contains(t, err.Error(), "MISSING")
+}
+
+func TestGetVariable_Forbidden(t *testing.T) {
+	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message":"403 Forbidden"}`)
+	})
+
+	_, err := client.GetVariable(context.Background(), "7", "MY_VAR", "")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
 func TestCreateVariable_Error(t *testing.T) {
 	_, client := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -254,6 +292,29 @@ func TestFilterByScope_WildcardTarget(t *testing.T) {
 	assert.Equal(t, "B", got[0].Key)
 }
 
+func TestFilterByScope_WildcardPatterns(t *testing.T) {
+	vars := []Variable{
+		{Key: "A", EnvironmentScope: "review/*"},
+		{Key: "B", EnvironmentScope: "*"},
+		{Key: "C", EnvironmentScope: "review/feature-x"},
+		{Key: "D", EnvironmentScope: "prod-*"},
+		{Key: "E", EnvironmentScope: "review/other"},
+	}
+
+	got := FilterByScope(vars, "review/feature-x")
+	keys := make([]string, 0, len(got))
+	for _, v := range got {
+		keys = append(keys, v.Key)
+	}
+	assert.Equal(t, []string{"A", "B", "C"}, keys)
+
+	// A wildcard target only sees itself and the global scope.
+	got = FilterByScope(vars, "review/*")
+	require.Len(t, got, 2)
+	assert.Equal(t, "A", got[0].Key)
+	assert.Equal(t, "B", got[1].Key)
+}
+
 func TestFilterByScope_EmptyScope(t *testing.T) {
 	vars := []Variable{
 		{Key: "A", EnvironmentScope: "production"},
@@ -0,0 +1,145 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/ohmylock/glenv/pkg/envfile"
+	glsync "github.com/ohmylock/glenv/pkg/sync"
+)
+
+// Stage identifies when a hook runs.
+type Stage string
+
+const (
+	// PreDiff runs after the .env file is parsed, before the remote diff.
+	PreDiff Stage = "pre_diff"
+	// PreApply runs after the diff is confirmed, before any change is applied.
+	PreApply Stage = "pre_apply"
+	// PostApply runs after all changes were applied, including failed ones.
+	PostApply Stage = "post_apply"
+)
+
+// Variable is a local variable as passed to pre_diff hooks.
+type Variable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Report summarizes an apply for post_apply hooks.
+type Report struct {
+	Created   int      `json:"created"`
+	Updated   int      `json:"updated"`
+	Deleted   int      `json:"deleted"`
+	Unchanged int      `json:"unchanged"`
+	Skipped   int      `json:"skipped"`
+	Failed    int      `json:"failed"`
+	Conflicts int      `json:"conflicts"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// Payload is the JSON document written to a hook's stdin. Values are in plain
+// text: hooks are local commands configured by the user, and validating a new
+// value (e.g. connecting with a DSN) is their main purpose.
+type Payload struct {
+	Stage       Stage                 `json:"stage"`
+	ProjectID   string                `json:"project_id"`
+	Environment string                `json:"environment"`
+	File        string                `json:"file"`
+	DryRun      bool                  `json:"dry_run"`
+	Variables   []Variable            `json:"variables,omitempty"` // pre_diff only
+	Changes     []glsync.ChangeRecord `json:"changes,omitempty"`   // pre_apply and post_apply
+	Report      *Report               `json:"report,omitempty"`    // post_apply only
+}
+
+// NewVariables converts parsed .env variables for a pre_diff payload.
+func NewVariables(vars []envfile.Variable) []Variable {
+	out := make([]Variable, 0, len(vars))
+	for _, v := range vars {
+		out = append(out, Variable{Key: v.Key, Value: v.Value})
+	}
+	return out
+}
+
+// NewChanges converts a diff for a pre_apply or post_apply payload.
+func NewChanges(diff glsync.DiffResult) []glsync.ChangeRecord {
+	out := make([]glsync.ChangeRecord, 0, len(diff.Changes))
+	for _, ch := range diff.Changes {
+		out = append(out, ch.Record())
+	}
+	return out
+}
+
+// NewReport converts a sync report for a post_apply payload.
+func NewReport(r glsync.SyncReport) *Report {
+	rep := &Report{
+		Created:   r.Created,
+		Updated:   r.Updated,
+		Deleted:   r.Deleted,
+		Unchanged: r.Unchanged,
+		Skipped:   r.Skipped,
+		Failed:    r.Failed,
+		Conflicts: r.Conflicts,
+	}
+	for _, err := range r.Errors {
+		rep.Errors = append(rep.Errors, err.Error())
+	}
+	return rep
+}
+
+// Runner executes hook commands through the shell.
+type Runner struct {
+	Shell  string    // defaults to "sh"
+	Stdout io.Writer // defaults to os.Stdout
+	Stderr io.Writer // defaults to os.Stderr
+}
+
+// Run executes commands in order with p as JSON on stdin and stops at the
+// first command that fails. Besides the payload, each command gets
+// GLENV_HOOK, GLENV_PROJECT_ID, GLENV_ENVIRONMENT and GLENV_DRY_RUN in its
+// environment.
+func (r Runner) Run(ctx context.Context, commands []string, p Payload) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	input, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("hooks: %s: encode payload: %w", p.Stage, err)
+	}
+
+	shell := r.Shell
+	if shell == "" {
+		shell = "sh"
+	}
+	stdout, stderr := r.Stdout, r.Stderr
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	env := append(os.Environ(),
+		"GLENV_HOOK="+string(p.Stage),
+		"GLENV_PROJECT_ID="+p.ProjectID,
+		"GLENV_ENVIRONMENT="+p.Environment,
+		"GLENV_DRY_RUN="+strconv.FormatBool(p.DryRun),
+	)
+	for _, command := range commands {
+		cmd := exec.CommandContext(ctx, shell, "-c", command) //nolint:gosec // G204: hook commands come from user config, expected behavior
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		cmd.Env = env
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("hooks: %s: %q: %w", p.Stage, command, err)
+		}
+	}
+	return nil
+}
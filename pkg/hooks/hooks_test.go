@@ -0,0 +1,59 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"testing"
+
+	glsync "github.com/ohmylock/glenv/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_PayloadOnStdin(t *testing.T) {
+	var out bytes.Buffer
+	r := Runner{Stdout: &out}
+	p := Payload{
+		Stage:       PreApply,
+		ProjectID:   "42",
+		Environment: "production",
+		Changes:     []glsync.ChangeRecord{{Kind: glsync.ChangeCreate, Key: "DB_DSN", NewValue: "postgres://db"}},
+	}
+
+	err := r.Run(context.Background(), []string{`cat; echo; echo "$GLENV_HOOK $GLENV_ENVIRONMENT"`}, p)
+	require.NoError(t, err)
+
+	lines := bytes.SplitN(out.Bytes(), []byte("\n"), 2)
+	var got Payload
+	require.NoError(t, json.Unmarshal(lines[0], &got))
+	assert.Equal(t, p, got)
+	assert.Equal(t, "pre_apply production\n", string(lines[1]))
+}
+
+func TestRun_StopsAtFirstFailure(t *testing.T) {
+	var out bytes.Buffer
+	r := Runner{Stdout: &out}
+
+	err := r.Run(context.Background(), []string{"echo first", "exit 3", "echo never"}, Payload{Stage: PreDiff})
+	require.Error(t, err)
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 3, exitErr.ExitCode())
+	assert.Contains(t, err.Error(), `pre_diff: "exit 3"`)
+	assert.Equal(t, "first\n", out.String())
+}
+
+func TestRun_NoCommands(t *testing.T) {
+	r := Runner{Shell: "/nonexistent"}
+	assert.NoError(t, r.Run(context.Background(), nil, Payload{Stage: PostApply}))
+}
+
+func TestNewReport(t *testing.T) {
+	rep := NewReport(glsync.SyncReport{Created: 2, Failed: 1, Errors: []error{errors.New("boom")}})
+	assert.Equal(t, 2, rep.Created)
+	assert.Equal(t, 1, rep.Failed)
+	assert.Equal(t, []string{"boom"}, rep.Errors)
+}
@@ -0,0 +1,118 @@
+package refs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ohmylock/glenv/pkg/envfile"
+)
+
+// Prefix marks a value as a reference to be resolved at sync time.
+const Prefix = "ref+"
+
+// Supported reference schemes.
+const (
+	SchemeFile = "file"
+	SchemeCmd  = "cmd"
+	SchemeEnv  = "env"
+)
+
+// IsReference reports whether value is a reference such as "ref+env://NAME".
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// Resolver replaces references in parsed variables with the values they
+// point to:
+//
+//   - ref+file://PATH  contents of a file, relative to Dir unless absolute
+//   - ref+cmd://CMD    stdout of a shell command
+//   - ref+env://NAME   an environment variable of the glenv process
+//
+// One trailing newline is stripped from file contents and command output.
+type Resolver struct {
+	Dir    string                          // base directory for relative file paths; "" means the working directory
+	Getenv func(key string) (string, bool) // defaults to os.LookupEnv
+}
+
+// Resolve returns a copy of parsed with all references resolved. Every
+// failing reference is reported; resolved values never appear in errors.
+func (r Resolver) Resolve(ctx context.Context, parsed *envfile.ParseResult) (*envfile.ParseResult, error) {
+	out := &envfile.ParseResult{
+		Variables: make([]envfile.Variable, len(parsed.Variables)),
+		Skipped:   parsed.Skipped,
+	}
+	copy(out.Variables, parsed.Variables)
+
+	var errs []error
+	for i, v := range out.Variables {
+		if !IsReference(v.Value) {
+			continue
+		}
+		value, err := r.resolve(ctx, v.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %s: %s: %w", v.Line, v.Key, v.Value, err))
+			continue
+		}
+		out.Variables[i].Value = value
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("refs: %w", errors.Join(errs...))
+	}
+	return out, nil
+}
+
+func (r Resolver) resolve(ctx context.Context, ref string) (string, error) {
+	scheme, target, ok := strings.Cut(strings.TrimPrefix(ref, Prefix), "://")
+	if !ok || target == "" {
+		return "", errors.New("malformed reference, want ref+SCHEME://TARGET")
+	}
+
+	switch scheme {
+	case SchemeFile:
+		path := target
+		if !filepath.IsAbs(path) && r.Dir != "" {
+			path = filepath.Join(r.Dir, path)
+		}
+		data, err := os.ReadFile(path) //nolint:gosec // G304: path comes from the user's .env file, expected behavior
+		if err != nil {
+			return "", err
+		}
+		return trimNewline(string(data)), nil
+
+	case SchemeCmd:
+		var stdout bytes.Buffer
+		cmd := exec.CommandContext(ctx, "sh", "-c", target) //nolint:gosec // G204: command comes from the user's .env file, expected behavior
+		cmd.Stdout = &stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return "", err
+		}
+		return trimNewline(stdout.String()), nil
+
+	case SchemeEnv:
+		getenv := r.Getenv
+		if getenv == nil {
+			getenv = os.LookupEnv
+		}
+		value, ok := getenv(target)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", target)
+		}
+		return value, nil
+
+	default:
+		return "", fmt.Errorf("unsupported scheme %q (want file, cmd or env)", scheme)
+	}
+}
+
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
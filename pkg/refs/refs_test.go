@@ -0,0 +1,71 @@
+package refs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ohmylock/glenv/pkg/envfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "secrets"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secrets", "db.txt"), []byte("file-secret\n"), 0o600))
+
+	r := Resolver{
+		Dir: dir,
+		Getenv: func(k string) (string, bool) {
+			v, ok := map[string]string{"CI_DB_PASS": "env-secret"}[k]
+			return v, ok
+		},
+	}
+	parsed := &envfile.ParseResult{Variables: []envfile.Variable{
+		{Key: "PLAIN", Value: "value", Line: 1},
+		{Key: "FROM_FILE", Value: "ref+file://secrets/db.txt", Line: 2},
+		{Key: "FROM_CMD", Value: "ref+cmd://echo cmd-secret", Line: 3},
+		{Key: "FROM_ENV", Value: "ref+env://CI_DB_PASS", Line: 4},
+	}}
+
+	got, err := r.Resolve(context.Background(), parsed)
+	require.NoError(t, err)
+	values := make(map[string]string)
+	for _, v := range got.Variables {
+		values[v.Key] = v.Value
+	}
+	assert.Equal(t, map[string]string{
+		"PLAIN":     "value",
+		"FROM_FILE": "file-secret",
+		"FROM_CMD":  "cmd-secret",
+		"FROM_ENV":  "env-secret",
+	}, values)
+	assert.Equal(t, "ref+env://CI_DB_PASS", parsed.Variables[3].Value, "input is not modified")
+}
+
+func TestResolve_Errors(t *testing.T) {
+	r := Resolver{Dir: t.TempDir(), Getenv: func(string) (string, bool) { return "", false }}
+	parsed := &envfile.ParseResult{Variables: []envfile.Variable{
+		{Key: "A", Value: "ref+env://MISSING", Line: 1},
+		{Key: "B", Value: "ref+file://nope.txt", Line: 2},
+		{Key: "C", Value: "ref+cmd://echo leaked; exit 1", Line: 3},
+		{Key: "D", Value: "ref+vault://secret/x", Line: 4},
+		{Key: "E", Value: "ref+env:MISSING", Line: 5},
+	}}
+
+	_, err := r.Resolve(context.Background(), parsed)
+	require.Error(t, err)
+	msg := err.Error()
+	assert.Contains(t, msg, "line 1: A: ref+env://MISSING: environment variable MISSING is not set")
+	assert.Contains(t, msg, "line 2: B: ref+file://nope.txt:")
+	assert.Contains(t, msg, "line 3: C:")
+	assert.Contains(t, msg, `line 4: D: ref+vault://secret/x: unsupported scheme "vault"`)
+	assert.Contains(t, msg, "line 5: E: ref+env:MISSING: malformed reference")
+}
+
+func TestIsReference(t *testing.T) {
+	assert.True(t, IsReference("ref+env://X"))
+	assert.False(t, IsReference("reference"))
+}
@@ -0,0 +1,214 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ohmylock/glenv/pkg/envfile"
+	"github.com/ohmylock/glenv/pkg/gitlab"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is the schema file looked up in the working directory when
+// neither a path nor a config section is given.
+const DefaultFile = ".glenv.schema.yml"
+
+// Value types supported by Rule.Type.
+const (
+	TypeString   = "string"
+	TypeInt      = "int"
+	TypeBool     = "bool"
+	TypeURL      = "url"
+	TypeDuration = "duration"
+	TypeEnum     = "enum"
+	TypeRegex    = "regex"
+)
+
+// Rule describes the constraints on a single variable.
+type Rule struct {
+	Type string `yaml:"type"` // defaults to "string"
+	// Required makes the key mandatory in every environment.
+	Required bool `yaml:"required"`
+	// RequiredIn makes the key mandatory in environments matching one of
+	// these scopes (GitLab syntax, e.g. "production" or "review/*").
+	RequiredIn []string `yaml:"required_in"`
+	// Scopes restricts the environments the key may be synced to. Empty
+	// means any environment.
+	Scopes  []string `yaml:"scopes"`
+	Values  []string `yaml:"values"`  // allowed values for "enum"
+	Pattern string   `yaml:"pattern"` // regular expression for "regex"
+
+	re *regexp.Regexp
+}
+
+// Schema declares the expected variables of a project.
+type Schema struct {
+	Variables map[string]*Rule `yaml:"variables"`
+}
+
+// Violation is a single schema error.
+type Violation struct {
+	Key     string
+	Line    int // 0 when the key is missing from the file
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", v.Line, v.Key, v.Message)
+	}
+	return fmt.Sprintf("%s: %s", v.Key, v.Message)
+}
+
+// UnmarshalYAML decodes and checks a schema, so that an invalid schema in a
+// config file is reported when the config is loaded.
+func (s *Schema) UnmarshalYAML(node *yaml.Node) error {
+	type plain Schema
+	if err := node.Decode((*plain)(s)); err != nil {
+		return err
+	}
+	return s.compile()
+}
+
+// Load reads and checks a schema file.
+func Load(path string) (*Schema, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: file path comes from user config, expected behavior
+	if err != nil {
+		return nil, fmt.Errorf("schema: read %q: %w", path, err)
+	}
+	var s Schema
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("schema: parse %q: %w", path, err)
+	}
+	return &s, nil
+}
+
+// compile validates rule definitions and prepares regular expressions.
+func (s *Schema) compile() error {
+	var errs []error
+	for key, r := range s.Variables {
+		if r == nil {
+			r = &Rule{}
+			s.Variables[key] = r
+		}
+		if r.Type == "" {
+			r.Type = TypeString
+		}
+		switch r.Type {
+		case TypeString, TypeInt, TypeBool, TypeURL, TypeDuration:
+		case TypeEnum:
+			if len(r.Values) == 0 {
+				errs = append(errs, fmt.Errorf("schema: %s: enum requires values", key))
+			}
+		case TypeRegex:
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("schema: %s: invalid pattern: %w", key, err))
+				continue
+			}
+			r.re = re
+		default:
+			errs = append(errs, fmt.Errorf("schema: %s: unknown type %q", key, r.Type))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks parsed variables for environment against the schema and
+// returns the violations sorted by key. Keys skipped by the parser as
+// placeholders count as missing. Keys not declared in the schema are allowed.
+func (s *Schema) Validate(parsed *envfile.ParseResult, environment string) []Violation {
+	var out []Violation
+
+	present := make(map[string]bool, len(parsed.Variables))
+	for _, v := range parsed.Variables {
+		present[v.Key] = true
+		r, ok := s.Variables[v.Key]
+		if !ok {
+			continue
+		}
+		if len(r.Scopes) > 0 && !matchesAny(r.Scopes, environment) {
+			out = append(out, Violation{Key: v.Key, Line: v.Line,
+				Message: fmt.Sprintf("not allowed in environment %q (allowed: %s)", environment, strings.Join(r.Scopes, ", "))})
+		}
+		if msg := r.check(v.Value); msg != "" {
+			out = append(out, Violation{Key: v.Key, Line: v.Line, Message: msg})
+		}
+	}
+
+	placeholders := make(map[string]int)
+	for _, sk := range parsed.Skipped {
+		if sk.Reason == envfile.SkipPlaceholder {
+			placeholders[sk.Key] = sk.Line
+		}
+	}
+	for key, r := range s.Variables {
+		if present[key] || !r.requiredIn(environment) {
+			continue
+		}
+		if line, ok := placeholders[key]; ok {
+			out = append(out, Violation{Key: key, Line: line, Message: "required but has a placeholder value"})
+			continue
+		}
+		out = append(out, Violation{Key: key, Message: "required but missing"})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+func (r *Rule) requiredIn(environment string) bool {
+	return r.Required || matchesAny(r.RequiredIn, environment)
+}
+
+// check returns a description of why value does not satisfy r, or "". The
+// value itself is left out since it may be a secret printed to CI logs.
+func (r *Rule) check(value string) string {
+	switch r.Type {
+	case TypeInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return "value is not an integer"
+		}
+	case TypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "value is not a boolean"
+		}
+	case TypeURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "value is not an absolute URL"
+		}
+	case TypeDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return "value is not a duration (e.g. 30s, 5m)"
+		}
+	case TypeEnum:
+		if !slices.Contains(r.Values, value) {
+			return "value is not one of " + strings.Join(r.Values, ", ")
+		}
+	case TypeRegex:
+		if !r.re.MatchString(value) {
+			return "value does not match " + r.Pattern
+		}
+	}
+	return ""
+}
+
+// matchesAny reports whether environment matches one of the scopes. A pattern
+// such as "review/*" also matches a sync targeting that pattern itself.
+func matchesAny(scopes []string, environment string) bool {
+	for _, s := range scopes {
+		if s == environment || gitlab.ScopeMatches(s, environment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ohmylock/glenv/pkg/envfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const testSchema = `
+variables:
+  PORT:
+    type: int
+    required: true
+  DEBUG:
+    type: bool
+  SENTRY_DSN:
+    type: url
+    required_in: [production, "staging-*"]
+  TIMEOUT:
+    type: duration
+  LOG_LEVEL:
+    type: enum
+    values: [debug, info, warn, error]
+  RELEASE:
+    type: regex
+    pattern: '^v\d+\.\d+\.\d+$'
+  DEBUG_TOKEN:
+    scopes: [development, "review/*"]
+`
+
+func parseSchema(t *testing.T, src string) *Schema {
+	t.Helper()
+	var s Schema
+	require.NoError(t, yaml.Unmarshal([]byte(src), &s))
+	return &s
+}
+
+func messages(vs []Violation) []string {
+	out := make([]string, 0, len(vs))
+	for _, v := range vs {
+		out = append(out, v.String())
+	}
+	return out
+}
+
+func TestValidate_Types(t *testing.T) {
+	s := parseSchema(t, testSchema)
+	parsed := &envfile.ParseResult{Variables: []envfile.Variable{
+		{Key: "PORT", Value: "80a", Line: 1},
+		{Key: "DEBUG", Value: "yes", Line: 2},
+		{Key: "TIMEOUT", Value: "30", Line: 3},
+		{Key: "LOG_LEVEL", Value: "trace", Line: 4},
+		{Key: "RELEASE", Value: "1.2.3", Line: 5},
+		{Key: "UNDECLARED", Value: "anything", Line: 6},
+	}}
+
+	got := messages(s.Validate(parsed, "development"))
+	assert.Equal(t, []string{
+		`line 2: DEBUG: value is not a boolean`,
+		`line 4: LOG_LEVEL: value is not one of debug, info, warn, error`,
+		`line 1: PORT: value is not an integer`,
+		`line 5: RELEASE: value does not match ^v\d+\.\d+\.\d+$`,
+		`line 3: TIMEOUT: value is not a duration (e.g. 30s, 5m)`,
+	}, got)
+}
+
+func TestValidate_MessagesOmitValues(t *testing.T) {
+	s := parseSchema(t, testSchema)
+	parsed := &envfile.ParseResult{Variables: []envfile.Variable{
+		{Key: "PORT", Value: "hunter2-port", Line: 1},
+		{Key: "RELEASE", Value: "hunter2-release", Line: 2},
+	}}
+
+	vs := s.Validate(parsed, "development")
+	require.Len(t, vs, 2)
+	for _, v := range vs {
+		assert.NotContains(t, v.String(), "hunter2")
+	}
+}
+
+func TestValidate_ValidValues(t *testing.T) {
+	s := parseSchema(t, testSchema)
+	parsed := &envfile.ParseResult{Variables: []envfile.Variable{
+		{Key: "PORT", Value: "8080"},
+		{Key: "DEBUG", Value: "false"},
+		{Key: "SENTRY_DSN", Value: "https://key@sentry.io/1"},
+		{Key: "TIMEOUT", Value: "1m30s"},
+		{Key: "LOG_LEVEL", Value: "info"},
+		{Key: "RELEASE", Value: "v1.2.3"},
+	}}
+
+	assert.Empty(t, s.Validate(parsed, "production"))
+}
+
+func TestValidate_RequiredPerEnvironment(t *testing.T) {
+	s := parseSchema(t, testSchema)
+	parsed := &envfile.ParseResult{
+		Variables: []envfile.Variable{{Key: "PORT", Value: "8080"}},
+		Skipped:   []envfile.SkippedLine{{Line: 7, Key: "SENTRY_DSN", Reason: envfile.SkipPlaceholder}},
+	}
+
+	assert.Empty(t, s.Validate(parsed, "development"))
+	assert.Equal(t, []string{"line 7: SENTRY_DSN: required but has a placeholder value"},
+		messages(s.Validate(parsed, "staging-eu")))
+
+	parsed.Skipped = nil
+	assert.Equal(t, []string{"SENTRY_DSN: required but missing"}, messages(s.Validate(parsed, "production")))
+	assert.Equal(t, []string{"PORT: required but missing"},
+		messages(s.Validate(&envfile.ParseResult{}, "development")))
+}
+
+func TestValidate_AllowedScopes(t *testing.T) {
+	s := parseSchema(t, testSchema)
+	parsed := &envfile.ParseResult{Variables: []envfile.Variable{
+		{Key: "PORT", Value: "8080"},
+		{Key: "SENTRY_DSN", Value: "https://sentry.io/1"},
+		{Key: "DEBUG_TOKEN", Value: "t", Line: 2},
+	}}
+
+	assert.Empty(t, s.Validate(parsed, "review/feature-x"))
+	assert.Equal(t, []string{`line 2: DEBUG_TOKEN: not allowed in environment "production" (allowed: development, review/*)`},
+		messages(s.Validate(parsed, "production")))
+}
+
+func TestSchema_InvalidDefinitions(t *testing.T) {
+	tests := map[string]string{
+		"unknown type":  "variables:\n  A:\n    type: float\n",
+		"enum no value": "variables:\n  A:\n    type: enum\n",
+		"bad regex":     "variables:\n  A:\n    type: regex\n    pattern: '('\n",
+	}
+	for name, src := range tests {
+		t.Run(name, func(t *testing.T) {
+			var s Schema
+			assert.Error(t, yaml.Unmarshal([]byte(src), &s))
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	require.NoError(t, os.WriteFile(path, []byte(testSchema), 0o600))
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	assert.Len(t, s.Variables, 7)
+	assert.Equal(t, TypeString, s.Variables["DEBUG_TOKEN"].Type)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yml"))
+	assert.Error(t, err)
+}
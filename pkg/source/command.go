@@ -0,0 +1,35 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/ohmylock/glenv/pkg/envfile"
+)
+
+// Command runs a shell command that prints dotenv to stdout, e.g.
+// "sops -d .env.enc". Its stderr is passed through.
+type Command struct {
+	Command string
+}
+
+// Load runs the command with sh -c and parses its output.
+func (c Command) Load(ctx context.Context) (*envfile.ParseResult, error) {
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.Command) //nolint:gosec // G204: command comes from user config, expected behavior
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("source: command %q: %w", c.Command, err)
+	}
+	result, err := envfile.ParseReader(&stdout)
+	if err != nil {
+		return nil, fmt.Errorf("source: command %q: %w", c.Command, err)
+	}
+	return result, nil
+}
+
+func (c Command) String() string { return "command:" + c.Command }
@@ -0,0 +1,56 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ohmylock/glenv/pkg/envfile"
+)
+
+// Dir reads a directory holding one file per variable, as used for Docker
+// and Kubernetes secrets: the file name is the key and the content is the
+// value. Hidden files and subdirectories are ignored, and a single trailing
+// newline is stripped from each value. Other file names that are not valid
+// variable names, such as config.json, fail the load.
+type Dir struct {
+	Path string
+}
+
+// Load reads every regular file in the directory.
+func (d Dir) Load(_ context.Context) (*envfile.ParseResult, error) {
+	entries, err := os.ReadDir(d.Path)
+	if err != nil {
+		return nil, fmt.Errorf("source: dir %q: %w", d.Path, err)
+	}
+
+	result := &envfile.ParseResult{}
+	var invalid []string
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") || !e.Type().IsRegular() {
+			continue
+		}
+		if !envfile.ValidKey(name) {
+			invalid = append(invalid, name)
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(d.Path, name)) //nolint:gosec // G304: directory comes from user config, expected behavior
+		if err != nil {
+			return nil, fmt.Errorf("source: dir %q: %w", d.Path, err)
+		}
+		value := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+		result.Variables = append(result.Variables, envfile.Variable{Key: name, Value: value})
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("source: dir %q: invalid variable names (letters, digits and '_' only): %s",
+			d.Path, strings.Join(invalid, ", "))
+	}
+	sort.Slice(result.Variables, func(i, j int) bool { return result.Variables[i].Key < result.Variables[j].Key })
+	return result, nil
+}
+
+func (d Dir) String() string { return "dir:" + d.Path }
@@ -0,0 +1,54 @@
+package source
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ohmylock/glenv/pkg/config"
+	"github.com/ohmylock/glenv/pkg/envfile"
+)
+
+// Source provides the local variables to sync.
+type Source interface {
+	// Load reads all variables from the source.
+	Load(ctx context.Context) (*envfile.ParseResult, error)
+	// String describes the source for output, e.g. "vault:secret/app/prod".
+	String() string
+}
+
+// New builds the Source described by cfg.
+func New(cfg config.SourceConfig) (Source, error) {
+	switch cfg.Type {
+	case "", "file":
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("source: file: path is required")
+		}
+		return File{Path: cfg.Path}, nil
+	case "dir":
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("source: dir: path is required")
+		}
+		return Dir{Path: cfg.Path}, nil
+	case "command":
+		if cfg.Command == "" {
+			return nil, fmt.Errorf("source: command: command is required")
+		}
+		return Command{Command: cfg.Command}, nil
+	case "vault":
+		return NewVault(cfg)
+	default:
+		return nil, fmt.Errorf("source: unknown type %q (want file, dir, command or vault)", cfg.Type)
+	}
+}
+
+// File reads a .env file.
+type File struct {
+	Path string
+}
+
+// Load parses the file with envfile.ParseFile.
+func (f File) Load(_ context.Context) (*envfile.ParseResult, error) {
+	return envfile.ParseFile(f.Path)
+}
+
+func (f File) String() string { return f.Path }
@@ -0,0 +1,140 @@
+//nolint:errcheck // test file
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ohmylock/glenv/pkg/config"
+	"github.com/ohmylock/glenv/pkg/envfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func keyValues(r *envfile.ParseResult) map[string]string {
+	out := make(map[string]string, len(r.Variables))
+	for _, v := range r.Variables {
+		out[v.Key] = v.Value
+	}
+	return out
+}
+
+// fakeVault serves a single KV v2 secret at secret/data/app/prod.
+func fakeVault(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string][]string{"errors": {"permission denied"}})
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/app/prod" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string][]string{"errors": {}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"data": map[string]any{
+					"DB_PASSWORD": "s3cret",
+					"PORT":        8080,
+					"BIG_ID":      json.RawMessage(`12345678901234567890`),
+					"DEBUG":       false,
+				},
+				"metadata": map[string]any{"version": 3},
+			},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestVault_Load(t *testing.T) {
+	srv := fakeVault(t)
+	src, err := New(config.SourceConfig{Type: "vault", Address: srv.URL, Token: "root", Path: "app/prod"})
+	require.NoError(t, err)
+	assert.Equal(t, "vault:secret/app/prod", src.String())
+
+	result, err := src.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DB_PASSWORD": "s3cret", "PORT": "8080", "DEBUG": "false",
+		"BIG_ID": "12345678901234567890"}, keyValues(result))
+	assert.Equal(t, "BIG_ID", result.Variables[0].Key, "sorted by key")
+}
+
+func TestVault_Errors(t *testing.T) {
+	srv := fakeVault(t)
+
+	src, err := New(config.SourceConfig{Type: "vault", Address: srv.URL, Token: "wrong", Path: "app/prod"})
+	require.NoError(t, err)
+	_, err = src.Load(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "permission denied")
+
+	src, err = New(config.SourceConfig{Type: "vault", Address: srv.URL, Token: "root", Path: "app/missing"})
+	require.NoError(t, err)
+	_, err = src.Load(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+}
+
+func TestVault_EnvDefaults(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "https://vault.example.com")
+	t.Setenv("VAULT_TOKEN", "")
+	_, err := New(config.SourceConfig{Type: "vault", Path: "app"})
+	require.Error(t, err, "token is required")
+
+	t.Setenv("VAULT_TOKEN", "tok")
+	src, err := New(config.SourceConfig{Type: "vault", Path: "app"})
+	require.NoError(t, err)
+	v := src.(*Vault)
+	assert.Equal(t, "https://vault.example.com", v.Address)
+	assert.Equal(t, "secret", v.Mount)
+}
+
+func TestDir_Load(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "DB_PASSWORD"), []byte("s3cret\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "TLS_CERT"), []byte("line1\nline2\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte("x"), 0o600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o700))
+
+	result, err := Dir{Path: dir}.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DB_PASSWORD": "s3cret", "TLS_CERT": "line1\nline2"}, keyValues(result))
+}
+
+func TestDir_InvalidKey(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "DB_PASSWORD"), []byte("s3cret"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte("{}"), 0o600))
+
+	_, err := Dir{Path: dir}.Load(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "config.json")
+}
+
+func TestCommand_Load(t *testing.T) {
+	result, err := Command{Command: `printf 'A=1\nB="two words"\n'`}.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "1", "B": "two words"}, keyValues(result))
+
+	_, err = Command{Command: "exit 2"}.Load(context.Background())
+	assert.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	src, err := New(config.SourceConfig{Path: ".env.prod"})
+	require.NoError(t, err)
+	assert.Equal(t, File{Path: ".env.prod"}, src)
+
+	_, err = New(config.SourceConfig{Type: "s3"})
+	assert.Error(t, err)
+	_, err = New(config.SourceConfig{Type: "command"})
+	assert.Error(t, err)
+}
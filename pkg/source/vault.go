@@ -0,0 +1,139 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ohmylock/glenv/pkg/config"
+	"github.com/ohmylock/glenv/pkg/envfile"
+)
+
+// defaultVaultMount is the mount path of the KV v2 engine enabled by default.
+const defaultVaultMount = "secret"
+
+// defaultVaultTimeout is the request timeout of a Vault source built without
+// an HTTP client.
+const defaultVaultTimeout = 30 * time.Second
+
+// Vault reads a secret from the HashiCorp Vault KV v2 HTTP API. Every field
+// of the secret becomes a variable.
+type Vault struct {
+	Address   string
+	Token     string
+	Mount     string
+	Path      string
+	Namespace string       // Vault Enterprise namespace, optional
+	HTTP      *http.Client // set by the CLI from the http config section
+}
+
+// NewVault builds a Vault source from cfg, falling back to the VAULT_ADDR,
+// VAULT_TOKEN and VAULT_NAMESPACE environment variables.
+func NewVault(cfg config.SourceConfig) (*Vault, error) {
+	v := &Vault{
+		Address:   firstNonEmpty(cfg.Address, os.Getenv("VAULT_ADDR")),
+		Token:     firstNonEmpty(cfg.Token, os.Getenv("VAULT_TOKEN")),
+		Mount:     firstNonEmpty(cfg.Mount, defaultVaultMount),
+		Path:      cfg.Path,
+		Namespace: firstNonEmpty(cfg.Namespace, os.Getenv("VAULT_NAMESPACE")),
+		HTTP:      &http.Client{Timeout: defaultVaultTimeout},
+	}
+	switch {
+	case v.Address == "":
+		return nil, fmt.Errorf("source: vault: address is required (set address or VAULT_ADDR)")
+	case v.Token == "":
+		return nil, fmt.Errorf("source: vault: token is required (set token or VAULT_TOKEN)")
+	case v.Path == "":
+		return nil, fmt.Errorf("source: vault: path is required")
+	}
+	return v, nil
+}
+
+// Load fetches the latest version of the secret.
+func (v *Vault) Load(ctx context.Context) (*envfile.ParseResult, error) {
+	apiURL := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(v.Address, "/"),
+		strings.Trim(v.Mount, "/"), strings.Trim(v.Path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("source: vault: build request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	client := v.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: defaultVaultTimeout}
+	}
+	resp, err := client.Do(req) //nolint:gosec // G704: Not SSRF - URL comes from trusted config
+	if err != nil {
+		return nil, fmt.Errorf("source: vault: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("source: vault: read %s: unexpected status %d%s", v, resp.StatusCode, vaultErrors(resp.Body))
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	// UseNumber keeps numbers as written; float64 would round large integers.
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		return nil, fmt.Errorf("source: vault: decode: %w", err)
+	}
+
+	result := &envfile.ParseResult{}
+	for key, raw := range body.Data.Data {
+		if !envfile.ValidKey(key) {
+			return nil, fmt.Errorf("source: vault: field %q is not a valid variable name (letters, digits and '_' only)", key)
+		}
+		value, ok := raw.(string)
+		if !ok {
+			// Numbers, booleans and nested objects are stored as JSON.
+			b, err := json.Marshal(raw)
+			if err != nil {
+				return nil, fmt.Errorf("source: vault: field %s: %w", key, err)
+			}
+			value = string(b)
+		}
+		result.Variables = append(result.Variables, envfile.Variable{Key: key, Value: value})
+	}
+	sort.Slice(result.Variables, func(i, j int) bool { return result.Variables[i].Key < result.Variables[j].Key })
+	return result, nil
+}
+
+func (v *Vault) String() string {
+	return "vault:" + strings.Trim(v.Mount, "/") + "/" + strings.Trim(v.Path, "/")
+}
+
+// vaultErrors extracts the "errors" list of a Vault error response.
+func vaultErrors(r io.Reader) string {
+	var body struct {
+		Errors []string `json:"errors"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r, 4096)).Decode(&body); err != nil || len(body.Errors) == 0 {
+		return ""
+	}
+	return ": " + strings.Join(body.Errors, "; ")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
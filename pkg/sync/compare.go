@@ -0,0 +1,97 @@
+package sync
+
+import (
+	"sort"
+
+	"github.com/ohmylock/glenv/pkg/gitlab"
+)
+
+// CompareKind classifies a key in a Comparison.
+type CompareKind string
+
+const (
+	CompareOnlyA   CompareKind = "only_a"
+	CompareOnlyB   CompareKind = "only_b"
+	CompareDiffers CompareKind = "differs"
+	CompareSame    CompareKind = "same"
+)
+
+// Comparison describes one key across two variable sets.
+type Comparison struct {
+	Kind CompareKind
+	Key  string
+	A, B *gitlab.Variable // nil when the key is missing on that side
+	// Differences names what differs for CompareDiffers:
+	// "value", "type", "masked" and/or "protected".
+	Differences []string
+}
+
+// Compare compares the variables jobs see in scopeA of a with those they see
+// in scopeB of b. Each side is filtered with gitlab.FilterByScope and the most
+// specific definition of each key is used. Values of masked and file
+// variables are compared by hash. The result is sorted by key.
+func Compare(a []gitlab.Variable, scopeA string, b []gitlab.Variable, scopeB string) []Comparison {
+	mapA := effectiveByKey(gitlab.FilterByScope(a, scopeA), scopeA)
+	mapB := effectiveByKey(gitlab.FilterByScope(b, scopeB), scopeB)
+
+	keys := make([]string, 0, len(mapA)+len(mapB))
+	for k := range mapA {
+		keys = append(keys, k)
+	}
+	for k := range mapB {
+		if _, ok := mapA[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	out := make([]Comparison, 0, len(keys))
+	for _, k := range keys {
+		va, okA := mapA[k]
+		vb, okB := mapB[k]
+		c := Comparison{Key: k}
+		switch {
+		case !okB:
+			c.Kind, c.A = CompareOnlyA, &va
+		case !okA:
+			c.Kind, c.B = CompareOnlyB, &vb
+		default:
+			c.A, c.B = &va, &vb
+			c.Differences = differences(va, vb)
+			c.Kind = CompareSame
+			if len(c.Differences) > 0 {
+				c.Kind = CompareDiffers
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// IsSecret reports whether v's value must not be displayed in comparisons.
+func IsSecret(v gitlab.Variable) bool {
+	return v.Masked || v.VariableType == "file"
+}
+
+func differences(a, b gitlab.Variable) []string {
+	var diffs []string
+	// Compare by hash when either side is secret, so that the plain values
+	// never take part in the comparison.
+	valA, valB := a.Value, b.Value
+	if IsSecret(a) || IsSecret(b) {
+		valA, valB = HashValue(valA), HashValue(valB)
+	}
+	if valA != valB {
+		diffs = append(diffs, "value")
+	}
+	if a.VariableType != b.VariableType {
+		diffs = append(diffs, "type")
+	}
+	if a.Masked != b.Masked {
+		diffs = append(diffs, "masked")
+	}
+	if a.Protected != b.Protected {
+		diffs = append(diffs, "protected")
+	}
+	return diffs
+}
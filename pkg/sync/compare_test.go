@@ -0,0 +1,51 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/ohmylock/glenv/pkg/gitlab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	vars := []gitlab.Variable{
+		{Key: "SHARED", Value: "same", EnvironmentScope: "*"},
+		{Key: "STAGING_ONLY", Value: "x", EnvironmentScope: "staging"},
+		{Key: "PROD_ONLY", Value: "y", EnvironmentScope: "production"},
+		{Key: "DB_HOST", Value: "db-staging", EnvironmentScope: "staging"},
+		{Key: "DB_HOST", Value: "db-prod", EnvironmentScope: "production"},
+		{Key: "API_TOKEN", Value: "tokenAAAAAAAA", Masked: true, EnvironmentScope: "staging"},
+		{Key: "API_TOKEN", Value: "tokenAAAAAAAA", Masked: true, Protected: true, EnvironmentScope: "production"},
+		{Key: "LOG_LEVEL", Value: "debug", EnvironmentScope: "*"},
+		{Key: "LOG_LEVEL", Value: "warn", EnvironmentScope: "production"},
+	}
+
+	got := Compare(vars, "staging", vars, "production")
+	byKey := make(map[string]Comparison)
+	for _, c := range got {
+		byKey[c.Key] = c
+	}
+	require.Len(t, got, 6)
+	assert.Equal(t, "API_TOKEN", got[0].Key, "sorted by key")
+
+	assert.Equal(t, CompareSame, byKey["SHARED"].Kind)
+	assert.Equal(t, CompareOnlyA, byKey["STAGING_ONLY"].Kind)
+	assert.Nil(t, byKey["STAGING_ONLY"].B)
+	assert.Equal(t, CompareOnlyB, byKey["PROD_ONLY"].Kind)
+	assert.Equal(t, []string{"value"}, byKey["DB_HOST"].Differences)
+	assert.Equal(t, []string{"protected"}, byKey["API_TOKEN"].Differences)
+
+	// The production-specific value wins over "*" on side B.
+	assert.Equal(t, "debug", byKey["LOG_LEVEL"].A.Value)
+	assert.Equal(t, "warn", byKey["LOG_LEVEL"].B.Value)
+}
+
+func TestCompare_MaskedByHash(t *testing.T) {
+	a := []gitlab.Variable{{Key: "SECRET", Value: "abcdefgh1", Masked: true, EnvironmentScope: "*"}}
+	b := []gitlab.Variable{{Key: "SECRET", Value: "abcdefgh1", EnvironmentScope: "*"}}
+
+	got := Compare(a, "*", b, "*")
+	require.Len(t, got, 1)
+	assert.Equal(t, []string{"masked"}, got[0].Differences, "equal values are not reported as a value difference")
+}
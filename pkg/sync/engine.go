@@ -2,7 +2,9 @@ package sync
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"sync"
 	"time"
 
@@ -31,13 +33,20 @@ type Change struct {
 	Classification string // human-readable tags, e.g. "masked", "protected", "file"
 	SkipReason     string
 	// Internal: used by Apply to pass classification data to the API call.
-	varType     string
-	masked      bool
-	protected   bool
-	raw         bool
-	envScope    string
+	varType   string
+	masked    bool
+	protected bool
+	raw       bool
+	envScope  string
+	oldHash   string // set instead of OldValue when restored from a plan
+	hashKey   string // the plan's salt oldHash was computed with
+	secret    bool   // key matches a secret pattern, even if the value is not maskable
 }
 
+// ErrConflict is returned (wrapped) when a remote variable changed between
+// Diff and Apply and DetectConflicts is enabled.
+var ErrConflict = errors.New("variable changed remotely since diff")
+
 // DiffResult holds the complete set of changes between local and remote.
 type DiffResult struct {
 	Changes []Change
@@ -57,9 +66,16 @@ type SyncReport struct {
 	Unchanged int
 	Skipped   int
 	Failed    int
+	Conflicts int
 	Duration  time.Duration
 	APICalls  int
 	Errors    []error
+	// RateLimit is the client's rate limiter state after applying; nil if the
+	// client does not report one.
+	RateLimit *gitlab.LimiterState
+	// Breaker is the client's circuit breaker state after applying; nil if
+	// the client does not report one.
+	Breaker *gitlab.BreakerState
 }
 
 // Options controls Engine behavior.
@@ -67,16 +83,33 @@ type Options struct {
 	Workers       int
 	DryRun        bool
 	DeleteMissing bool
+	// DetectConflicts re-fetches each variable before update or delete and
+	// refuses to touch it if its value no longer matches Change.OldValue.
+	DetectConflicts bool
+	// Logger receives a record for the start and end of every task; nil
+	// disables logging. Variable values are never logged.
+	Logger *slog.Logger
 }
 
 // gitlabClient is the subset of the gitlab.Client API used by the engine.
 // It is defined as an interface to allow test fakes.
 type gitlabClient interface {
+	GetVariable(ctx context.Context, projectID, key, envScope string) (*gitlab.Variable, error)
 	CreateVariable(ctx context.Context, projectID string, req gitlab.CreateRequest) (*gitlab.Variable, error)
 	UpdateVariable(ctx context.Context, projectID string, req gitlab.CreateRequest) (*gitlab.Variable, error)
 	DeleteVariable(ctx context.Context, projectID, key, envScope string) error
 }
 
+// limiterReporter is implemented by clients with an adaptive rate limiter.
+type limiterReporter interface {
+	LimiterState() gitlab.LimiterState
+}
+
+// breakerReporter is implemented by clients with a circuit breaker.
+type breakerReporter interface {
+	BreakerState() gitlab.BreakerState
+}
+
 // Engine orchestrates diff and apply operations.
 type Engine struct {
 	client     gitlabClient
@@ -90,6 +123,9 @@ func NewEngine(client gitlabClient, cl *classifier.Classifier, opts Options, pro
 	if opts.Workers <= 0 {
 		opts.Workers = 5
 	}
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.DiscardHandler)
+	}
 	return &Engine{
 		client:     client,
 		classifier: cl,
@@ -107,18 +143,9 @@ func (e *Engine) Diff(ctx context.Context, local []envfile.Variable, remote []gi
 	// filter the response ourselves before building the index.
 	remote = gitlab.FilterByScope(remote, envScope)
 
-	// Index remote by key for O(1) lookup.
-	// After filtering, remote contains only variables matching the target scope
-	// (exact match) or the wildcard "*". When both exist for the same key,
-	// prefer the exact-scope entry so that scopeMatch and value comparison
-	// operate on the precise variable rather than the wildcard one.
-	remoteMap := make(map[string]gitlab.Variable, len(remote))
-	for _, v := range remote {
-		existing, ok := remoteMap[v.Key]
-		if !ok || existing.EnvironmentScope == "*" {
-			remoteMap[v.Key] = v
-		}
-	}
+	// Index remote by key for O(1) lookup, keeping the variable jobs actually
+	// see so that scopeMatch and value comparison operate on it.
+	remoteMap := effectiveByKey(remote, envScope)
 
 	localKeys := make(map[string]struct{}, len(local))
 	var changes []Change
@@ -128,11 +155,13 @@ func (e *Engine) Diff(ctx context.Context, local []envfile.Variable, remote []gi
 		cl := e.classifier.Classify(lv.Key, lv.Value, envScope)
 
 		classLabel := buildClassLabel(cl)
+		secret := e.classifier.IsSecret(lv.Key)
 
 		rv, exists := remoteMap[lv.Key]
 		// scopeMatch checks if the remote variable matches the target environment scope.
-		// A match requires: remote exists AND (remote scope == target scope OR remote scope is "*").
-		scopeMatch := exists && (rv.EnvironmentScope == envScope || rv.EnvironmentScope == "*")
+		// A match requires: remote exists AND (remote scope == target scope OR
+		// remote scope is "*" OR a wildcard pattern matching the target).
+		scopeMatch := exists && (rv.EnvironmentScope == envScope || gitlab.ScopeMatches(rv.EnvironmentScope, envScope))
 
 		// Pre-compute final flag values to account for floor logic.
 		// This prevents triggering unnecessary updates when the final value
@@ -152,6 +181,7 @@ func (e *Engine) Diff(ctx context.Context, local []envfile.Variable, remote []gi
 				masked:         cl.Masked,
 				protected:      cl.Protected,
 				envScope:       envScope,
+				secret:         secret,
 			})
 		case rv.Value != lv.Value || rv.VariableType != cl.VarType || rv.Masked != finalMasked || rv.Protected != finalProtected:
 			// Floor logic: preserve existing Protected=true and Masked=true flags.
@@ -169,6 +199,7 @@ func (e *Engine) Diff(ctx context.Context, local []envfile.Variable, remote []gi
 				protected:      finalProtected,
 				raw:            rv.Raw,
 				envScope:       rv.EnvironmentScope,
+				secret:         secret,
 			})
 		default:
 			changes = append(changes, Change{
@@ -177,6 +208,10 @@ func (e *Engine) Diff(ctx context.Context, local []envfile.Variable, remote []gi
 				OldValue:       rv.Value,
 				NewValue:       lv.Value,
 				Classification: classLabel,
+				varType:        rv.VariableType,
+				masked:         rv.Masked,
+				protected:      rv.Protected,
+				raw:            rv.Raw,
 				envScope:       rv.EnvironmentScope,
 			})
 		}
@@ -208,6 +243,73 @@ func (e *Engine) Diff(ctx context.Context, local []envfile.Variable, remote []gi
 	return DiffResult{Changes: changes}
 }
 
+// effectiveByKey indexes vars by key. vars must already be filtered to those
+// visible in envScope: the exact scope, matching wildcard patterns (e.g.
+// "review/*") and "*". When several exist for the same key, the one GitLab
+// would pick (the most specific) is kept.
+func effectiveByKey(vars []gitlab.Variable, envScope string) map[string]gitlab.Variable {
+	m := make(map[string]gitlab.Variable, len(vars))
+	for _, v := range vars {
+		existing, ok := m[v.Key]
+		if !ok || gitlab.MoreSpecific(v.EnvironmentScope, existing.EnvironmentScope, envScope) {
+			m[v.Key] = v
+		}
+	}
+	return m
+}
+
+// DeleteChanges returns a DiffResult that deletes each of vars in its own scope.
+func DeleteChanges(vars []gitlab.Variable) DiffResult {
+	changes := make([]Change, 0, len(vars))
+	for _, v := range vars {
+		changes = append(changes, Change{
+			Kind:     ChangeDelete,
+			Key:      v.Key,
+			OldValue: v.Value,
+			envScope: v.EnvironmentScope,
+		})
+	}
+	return DiffResult{Changes: changes}
+}
+
+// Promote raises the classification of the change for key on top of what the
+// classifier decided. Like the floor logic in Diff it only ever turns flags on:
+// varType "file" switches the type, masked and protected are promoted
+// false→true. An unchanged entry becomes an update when its flags change.
+// It returns an error if masking is requested for a value GitLab cannot mask.
+func (d *DiffResult) Promote(key, varType string, masked, protected bool) error {
+	for i := range d.Changes {
+		ch := &d.Changes[i]
+		if ch.Key != key {
+			continue
+		}
+		if ch.Kind != ChangeCreate && ch.Kind != ChangeUpdate && ch.Kind != ChangeUnchanged {
+			return nil
+		}
+		if masked && !classifier.IsMaskable(ch.NewValue) {
+			return fmt.Errorf("%s: value cannot be masked by GitLab (needs 8+ characters from [a-zA-Z0-9_:@-.+~=/])", key)
+		}
+
+		newType := ch.varType
+		if varType != "" {
+			newType = varType
+		}
+		newMasked := ch.masked || masked
+		newProtected := ch.protected || protected
+		if newType == ch.varType && newMasked == ch.masked && newProtected == ch.protected {
+			return nil
+		}
+
+		ch.varType, ch.masked, ch.protected = newType, newMasked, newProtected
+		ch.Classification = buildClassLabelFromValues(newType, newMasked, newProtected)
+		if ch.Kind == ChangeUnchanged {
+			ch.Kind = ChangeUpdate
+		}
+		return nil
+	}
+	return nil
+}
+
 // Apply executes all changes in diff using a worker pool. It is equivalent to
 // ApplyWithCallback with a nil callback.
 func (e *Engine) Apply(ctx context.Context, diff DiffResult) SyncReport {
@@ -265,7 +367,7 @@ func (e *Engine) ApplyWithCallback(ctx context.Context, diff DiffResult, cb func
 					resultCh <- Result{Change: task, Error: ctx.Err()}
 					continue
 				}
-				resultCh <- e.applyOne(ctx, task)
+				resultCh <- e.applyLogged(ctx, task)
 			}
 		}()
 	}
@@ -283,6 +385,9 @@ func (e *Engine) ApplyWithCallback(ctx context.Context, diff DiffResult, cb func
 		}
 		if r.Error != nil {
 			report.Failed++
+			if errors.Is(r.Error, ErrConflict) {
+				report.Conflicts++
+			}
 			report.Errors = append(report.Errors, r.Error)
 			continue
 		}
@@ -295,20 +400,47 @@ func (e *Engine) ApplyWithCallback(ctx context.Context, diff DiffResult, cb func
 		case ChangeUpdate:
 			report.Updated++
 			if !e.opts.DryRun {
-				report.APICalls++
+				report.APICalls += e.callsPerMutation()
 			}
 		case ChangeDelete:
 			report.Deleted++
 			if !e.opts.DryRun {
-				report.APICalls++
+				report.APICalls += e.callsPerMutation()
 			}
 		}
 	}
 
+	if lr, ok := e.client.(limiterReporter); ok {
+		state := lr.LimiterState()
+		report.RateLimit = &state
+	}
+	if br, ok := e.client.(breakerReporter); ok {
+		state := br.BreakerState()
+		report.Breaker = &state
+	}
 	report.Duration = time.Since(start)
 	return report
 }
 
+// applyLogged runs applyOne with task start and finish log records.
+func (e *Engine) applyLogged(ctx context.Context, task Change) Result {
+	attrs := []any{
+		slog.String("kind", string(task.Kind)),
+		slog.String("key", task.Key),
+		slog.String("environment_scope", task.envScope),
+	}
+	e.opts.Logger.Debug("sync: task started", attrs...)
+	start := time.Now()
+	r := e.applyOne(ctx, task)
+	attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+	if r.Error != nil {
+		e.opts.Logger.Warn("sync: task failed", append(attrs, slog.Any("error", r.Error))...)
+	} else {
+		e.opts.Logger.Debug("sync: task finished", attrs...)
+	}
+	return r
+}
+
 // applyOne executes a single Change, routing to the appropriate API call.
 func (e *Engine) applyOne(ctx context.Context, task Change) Result {
 	switch task.Kind {
@@ -352,6 +484,9 @@ func (e *Engine) applyOne(ctx context.Context, task Change) Result {
 		if req.VariableType == "" {
 			req.VariableType = "env_var"
 		}
+		if err := e.checkConflict(ctx, task); err != nil {
+			return Result{Change: task, Error: fmt.Errorf("update %s: %w", task.Key, err)}
+		}
 		_, err := e.client.UpdateVariable(ctx, e.projectID, req)
 		if err != nil {
 			return Result{Change: task, Error: fmt.Errorf("update %s: %w", task.Key, err)}
@@ -362,6 +497,9 @@ func (e *Engine) applyOne(ctx context.Context, task Change) Result {
 		if e.opts.DryRun {
 			return Result{Change: task}
 		}
+		if err := e.checkConflict(ctx, task); err != nil {
+			return Result{Change: task, Error: fmt.Errorf("delete %s: %w", task.Key, err)}
+		}
 		err := e.client.DeleteVariable(ctx, e.projectID, task.Key, task.envScope)
 		if err != nil {
 			return Result{Change: task, Error: fmt.Errorf("delete %s: %w", task.Key, err)}
@@ -373,6 +511,86 @@ func (e *Engine) applyOne(ctx context.Context, task Change) Result {
 	}
 }
 
+// Relocate copies src to dstKey in dstScope, preserving value, type and flags,
+// and deletes src only after the copy was created. It refuses to overwrite an
+// existing destination variable. In dry-run mode only the destination check runs.
+func (e *Engine) Relocate(ctx context.Context, src gitlab.Variable, dstKey, dstScope string) error {
+	if src.Key == dstKey && src.EnvironmentScope == dstScope {
+		return fmt.Errorf("relocate %s: source and destination are the same", src.Key)
+	}
+
+	existing, err := e.client.GetVariable(ctx, e.projectID, dstKey, dstScope)
+	switch {
+	case errors.Is(err, gitlab.ErrNotFound):
+	case err != nil:
+		return fmt.Errorf("relocate %s: check destination: %w", src.Key, err)
+	case existing.EnvironmentScope == dstScope:
+		return fmt.Errorf("relocate %s: %s already exists in scope %q", src.Key, dstKey, dstScope)
+	}
+	if e.opts.DryRun {
+		return nil
+	}
+
+	varType := src.VariableType
+	if varType == "" {
+		varType = "env_var"
+	}
+	req := gitlab.CreateRequest{
+		Key:              dstKey,
+		Value:            src.Value,
+		VariableType:     varType,
+		EnvironmentScope: dstScope,
+		Protected:        src.Protected,
+		Masked:           src.Masked,
+		Raw:              src.Raw,
+	}
+	if _, err := e.client.CreateVariable(ctx, e.projectID, req); err != nil {
+		return fmt.Errorf("relocate %s: create %s: %w", src.Key, dstKey, err)
+	}
+	if err := e.client.DeleteVariable(ctx, e.projectID, src.Key, src.EnvironmentScope); err != nil {
+		return fmt.Errorf("relocate %s: %s was created but the original could not be deleted: %w", src.Key, dstKey, err)
+	}
+	return nil
+}
+
+// checkConflict re-fetches the variable targeted by task and returns an error
+// wrapping ErrConflict if it no longer holds the value the diff was computed
+// against. It is a no-op unless DetectConflicts is enabled.
+func (e *Engine) checkConflict(ctx context.Context, task Change) error {
+	if !e.opts.DetectConflicts {
+		return nil
+	}
+	current, err := e.client.GetVariable(ctx, e.projectID, task.Key, task.envScope)
+	if err != nil && !errors.Is(err, gitlab.ErrNotFound) {
+		return fmt.Errorf("fetch current value: %w", err)
+	}
+	// GitLab versions that ignore the scope filter return the key from
+	// another scope; the variable in task's scope is gone then as well.
+	if err != nil || current.EnvironmentScope != task.envScope {
+		return fmt.Errorf("%w: deleted in scope %q", ErrConflict, task.envScope)
+	}
+	if !task.matchesOld(current.Value) {
+		return fmt.Errorf("%w: value in scope %q was modified", ErrConflict, task.envScope)
+	}
+	return nil
+}
+
+// matchesOld reports whether value equals the remote value the change was diffed against.
+func (ch Change) matchesOld(value string) bool {
+	if ch.oldHash != "" {
+		return keyedHash(ch.hashKey, value) == ch.oldHash
+	}
+	return value == ch.OldValue
+}
+
+// callsPerMutation returns the number of API calls an update or delete costs.
+func (e *Engine) callsPerMutation() int {
+	if e.opts.DetectConflicts {
+		return 2
+	}
+	return 1
+}
+
 // buildClassLabel returns a human-readable classification string from a Classification.
 func buildClassLabel(cl classifier.Classification) string {
 	return buildClassLabelFromValues(cl.VarType, cl.Masked, cl.Protected)
@@ -1,8 +1,11 @@
 package sync
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -16,12 +19,21 @@ import (
 
 // fakeClient implements the gitlabClient interface for testing.
 type fakeClient struct {
+	getFn    func(ctx context.Context, projectID, key, envScope string) (*gitlab.Variable, error)
 	createFn func(ctx context.Context, projectID string, req gitlab.CreateRequest) (*gitlab.Variable, error)
 	updateFn func(ctx context.Context, projectID string, req gitlab.CreateRequest) (*gitlab.Variable, error)
 	deleteFn func(ctx context.Context, projectID, key, envScope string) error
 	calls    atomic.Int32
 }
 
+func (f *fakeClient) GetVariable(ctx context.Context, projectID, key, envScope string) (*gitlab.Variable, error) {
+	f.calls.Add(1)
+	if f.getFn != nil {
+		return f.getFn(ctx, projectID, key, envScope)
+	}
+	return nil, fmt.Errorf("get %s: %w", key, gitlab.ErrNotFound)
+}
+
 func (f *fakeClient) CreateVariable(ctx context.Context, projectID string, req gitlab.CreateRequest) (*gitlab.Variable, error) {
 	f.calls.Add(1)
 	if f.createFn != nil {
@@ -195,6 +207,76 @@ func TestApply_Concurrent(t *testing.T) {
 	assert.Equal(t, int32(20), fake.calls.Load())
 }
 
+// limitedClient is a fakeClient that reports rate limiter state.
+type limitedClient struct {
+	fakeClient
+}
+
+func (c *limitedClient) LimiterState() gitlab.LimiterState {
+	return gitlab.LimiterState{Rate: 2, MaxRate: 10, Limit: 600, Remaining: 40, Adjustments: 1}
+}
+
+func TestApply_ReportsLimiterState(t *testing.T) {
+	diff := DiffResult{Changes: []Change{{Kind: ChangeCreate, Key: "A", NewValue: "v"}}}
+
+	report := newTestEngine(&fakeClient{}, Options{Workers: 1}).Apply(context.Background(), diff)
+	assert.Nil(t, report.RateLimit)
+
+	report = newTestEngine(&limitedClient{}, Options{Workers: 1}).Apply(context.Background(), diff)
+	require.NotNil(t, report.RateLimit)
+	assert.True(t, report.RateLimit.Throttled())
+	assert.Equal(t, 40, report.RateLimit.Remaining)
+}
+
+// trippedClient is a fakeClient whose circuit breaker is open.
+type trippedClient struct {
+	fakeClient
+}
+
+func (c *trippedClient) BreakerState() gitlab.BreakerState {
+	return gitlab.BreakerState{State: gitlab.BreakerOpen, ConsecutiveFailures: 5, Trips: 1}
+}
+
+func TestApply_ReportsBreakerState(t *testing.T) {
+	client := &trippedClient{}
+	client.createFn = func(_ context.Context, _ string, _ gitlab.CreateRequest) (*gitlab.Variable, error) {
+		return nil, fmt.Errorf("gitlab: %w", gitlab.ErrCircuitOpen)
+	}
+	diff := DiffResult{Changes: []Change{
+		{Kind: ChangeCreate, Key: "A", NewValue: "v"},
+		{Kind: ChangeCreate, Key: "B", NewValue: "v"},
+	}}
+
+	report := newTestEngine(client, Options{Workers: 1}).Apply(context.Background(), diff)
+	assert.Equal(t, 2, report.Failed)
+	require.NotNil(t, report.Breaker)
+	assert.Equal(t, gitlab.BreakerOpen, report.Breaker.State)
+	for _, err := range report.Errors {
+		assert.ErrorIs(t, err, gitlab.ErrCircuitOpen)
+	}
+}
+
+func TestApply_LogsTasks(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := &fakeClient{}
+	client.updateFn = func(_ context.Context, _ string, _ gitlab.CreateRequest) (*gitlab.Variable, error) {
+		return nil, errors.New("gitlab: server error 502")
+	}
+	diff := DiffResult{Changes: []Change{
+		{Kind: ChangeCreate, Key: "A", NewValue: "new-secret"},
+		{Kind: ChangeUpdate, Key: "B", OldValue: "old-secret", NewValue: "new-secret"},
+	}}
+
+	newTestEngine(client, Options{Workers: 1, Logger: logger}).Apply(context.Background(), diff)
+
+	out := buf.String()
+	assert.Contains(t, out, `msg="sync: task started" kind=create key=A`)
+	assert.Contains(t, out, `msg="sync: task finished" kind=create key=A`)
+	assert.Contains(t, out, `msg="sync: task failed" kind=update key=B`)
+	assert.NotContains(t, out, "secret")
+}
+
 func TestApply_ContextCancel(t *testing.T) {
 	// Use a channel to block workers until we cancel.
 	// Each call to createFn blocks until ctx is canceled, then returns error.
@@ -612,3 +694,258 @@ func TestApply_WildcardScope_UpdatePassesCorrectScope(t *testing.T) {
 	require.Equal(t, 0, report.Failed)
 	assert.Equal(t, "*", capturedScope, "UpdateVariable must use the remote variable's actual scope as filter")
 }
+
+// --- Conflict detection tests ---
+
+func TestApply_DetectConflicts_ValueChanged(t *testing.T) {
+	var updated atomic.Bool
+	client := &fakeClient{
+		getFn: func(_ context.Context, _, key, envScope string) (*gitlab.Variable, error) {
+			return &gitlab.Variable{Key: key, Value: "edited_in_ui", EnvironmentScope: envScope}, nil
+		},
+		updateFn: func(_ context.Context, _ string, req gitlab.CreateRequest) (*gitlab.Variable, error) {
+			updated.Store(true)
+			return &gitlab.Variable{Key: req.Key}, nil
+		},
+	}
+	engine := newTestEngine(client, Options{DetectConflicts: true})
+
+	local := []envfile.Variable{{Key: "FOO", Value: "new_value"}}
+	remote := []gitlab.Variable{{Key: "FOO", Value: "old_value", VariableType: "env_var", EnvironmentScope: "*"}}
+	report := engine.Apply(context.Background(), engine.Diff(context.Background(), local, remote, "*"))
+
+	assert.False(t, updated.Load(), "update must not run on conflict")
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 1, report.Conflicts)
+	require.Len(t, report.Errors, 1)
+	assert.ErrorIs(t, report.Errors[0], ErrConflict)
+}
+
+func TestApply_DetectConflicts_DeletedRemotely(t *testing.T) {
+	engine := newTestEngine(&fakeClient{}, Options{DetectConflicts: true, DeleteMissing: true})
+
+	remote := []gitlab.Variable{{Key: "STALE", Value: "x", EnvironmentScope: "*"}}
+	report := engine.Apply(context.Background(), engine.Diff(context.Background(), nil, remote, "*"))
+
+	assert.Equal(t, 0, report.Deleted)
+	assert.Equal(t, 1, report.Conflicts)
+}
+
+func TestApply_DetectConflicts_OtherScopeReturned(t *testing.T) {
+	var updated atomic.Bool
+	client := &fakeClient{
+		// Older GitLab versions ignore the scope filter and return any scope.
+		getFn: func(_ context.Context, _, key, _ string) (*gitlab.Variable, error) {
+			return &gitlab.Variable{Key: key, Value: "old_value", EnvironmentScope: "*"}, nil
+		},
+		updateFn: func(_ context.Context, _ string, req gitlab.CreateRequest) (*gitlab.Variable, error) {
+			updated.Store(true)
+			return &gitlab.Variable{Key: req.Key}, nil
+		},
+	}
+	engine := newTestEngine(client, Options{DetectConflicts: true})
+
+	local := []envfile.Variable{{Key: "FOO", Value: "new_value"}}
+	remote := []gitlab.Variable{{Key: "FOO", Value: "old_value", VariableType: "env_var", EnvironmentScope: "production"}}
+	report := engine.Apply(context.Background(), engine.Diff(context.Background(), local, remote, "production"))
+
+	assert.False(t, updated.Load(), "update must not run on conflict")
+	assert.Equal(t, 1, report.Conflicts)
+	require.Len(t, report.Errors, 1)
+	assert.ErrorIs(t, report.Errors[0], ErrConflict)
+	assert.Contains(t, report.Errors[0].Error(), `deleted in scope "production"`)
+}
+
+func TestApply_DetectConflicts_Unchanged(t *testing.T) {
+	client := &fakeClient{
+		getFn: func(_ context.Context, _, key, envScope string) (*gitlab.Variable, error) {
+			return &gitlab.Variable{Key: key, Value: "old_value", EnvironmentScope: envScope}, nil
+		},
+	}
+	engine := newTestEngine(client, Options{DetectConflicts: true})
+
+	local := []envfile.Variable{{Key: "FOO", Value: "new_value"}}
+	remote := []gitlab.Variable{{Key: "FOO", Value: "old_value", VariableType: "env_var", EnvironmentScope: "*"}}
+	report := engine.Apply(context.Background(), engine.Diff(context.Background(), local, remote, "*"))
+
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 0, report.Failed)
+	assert.Equal(t, 2, report.APICalls, "get + update")
+}
+
+func TestApply_DetectConflicts_PlanHashedOldValue(t *testing.T) {
+	client := &fakeClient{
+		getFn: func(_ context.Context, _, key, envScope string) (*gitlab.Variable, error) {
+			return &gitlab.Variable{Key: key, Value: "old_value", EnvironmentScope: envScope}, nil
+		},
+	}
+	engine := newTestEngine(client, Options{DetectConflicts: true})
+
+	plan := &Plan{Version: PlanVersion, Salt: "salt"}
+	plan.Changes = []ChangeRecord{
+		{Kind: ChangeUpdate, Key: "FOO", OldValueHash: plan.HashValue("old_value"), NewValue: "new_value", EnvScope: "*"},
+	}
+	diff, err := plan.DiffResult(nil)
+	require.NoError(t, err)
+
+	report := engine.Apply(context.Background(), diff)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 0, report.Conflicts)
+}
+
+// --- Promote tests ---
+
+func TestPromote_UnchangedBecomesUpdate(t *testing.T) {
+	engine := newTestEngine(&fakeClient{}, Options{})
+
+	local := []envfile.Variable{{Key: "APP_NAME", Value: "glenv-app"}}
+	remote := []gitlab.Variable{{Key: "APP_NAME", Value: "glenv-app", VariableType: "env_var", EnvironmentScope: "*", Raw: true}}
+	diff := engine.Diff(context.Background(), local, remote, "*")
+	require.Equal(t, ChangeUnchanged, diff.Changes[0].Kind)
+
+	require.NoError(t, diff.Promote("APP_NAME", "", true, true))
+
+	ch := diff.Changes[0]
+	assert.Equal(t, ChangeUpdate, ch.Kind)
+	assert.True(t, ch.masked)
+	assert.True(t, ch.protected)
+	assert.True(t, ch.raw, "raw flag must be preserved")
+	assert.Equal(t, "env_var,masked,protected", ch.Classification)
+}
+
+func TestPromote_NeverDemotes(t *testing.T) {
+	engine := newTestEngine(&fakeClient{}, Options{})
+
+	local := []envfile.Variable{{Key: "DB_PASSWORD", Value: "supersecretvalue123"}}
+	diff := engine.Diff(context.Background(), local, nil, "production")
+	require.True(t, diff.Changes[0].masked)
+
+	require.NoError(t, diff.Promote("DB_PASSWORD", "", false, false))
+	assert.True(t, diff.Changes[0].masked)
+	assert.True(t, diff.Changes[0].protected)
+}
+
+func TestPromote_FileType(t *testing.T) {
+	engine := newTestEngine(&fakeClient{}, Options{})
+
+	diff := engine.Diff(context.Background(), []envfile.Variable{{Key: "CONFIG", Value: "a: b"}}, nil, "*")
+	require.NoError(t, diff.Promote("CONFIG", "file", false, false))
+	assert.Equal(t, "file", diff.Changes[0].varType)
+}
+
+func TestPromote_NotMaskable(t *testing.T) {
+	engine := newTestEngine(&fakeClient{}, Options{})
+
+	diff := engine.Diff(context.Background(), []envfile.Variable{{Key: "SHORT", Value: "abc"}}, nil, "*")
+	err := diff.Promote("SHORT", "", true, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be masked")
+}
+
+// --- Relocate tests ---
+
+func TestRelocate_CreateThenDelete(t *testing.T) {
+	var order []string
+	var created gitlab.CreateRequest
+	client := &fakeClient{
+		createFn: func(_ context.Context, _ string, req gitlab.CreateRequest) (*gitlab.Variable, error) {
+			order = append(order, "create")
+			created = req
+			return &gitlab.Variable{Key: req.Key}, nil
+		},
+		deleteFn: func(_ context.Context, _, key, envScope string) error {
+			order = append(order, "delete "+key+"@"+envScope)
+			return nil
+		},
+	}
+	engine := newTestEngine(client, Options{})
+
+	src := gitlab.Variable{Key: "DB_PASS", Value: "supersecret1", VariableType: "file", EnvironmentScope: "*", Masked: true, Protected: true, Raw: true}
+	require.NoError(t, engine.Relocate(context.Background(), src, "DATABASE_PASSWORD", "*"))
+
+	assert.Equal(t, []string{"create", "delete DB_PASS@*"}, order)
+	assert.Equal(t, gitlab.CreateRequest{
+		Key: "DATABASE_PASSWORD", Value: "supersecret1", VariableType: "file",
+		EnvironmentScope: "*", Masked: true, Protected: true, Raw: true,
+	}, created)
+}
+
+func TestRelocate_CreateFails_NoDelete(t *testing.T) {
+	var deleted atomic.Bool
+	client := &fakeClient{
+		createFn: func(context.Context, string, gitlab.CreateRequest) (*gitlab.Variable, error) {
+			return nil, fmt.Errorf("boom")
+		},
+		deleteFn: func(context.Context, string, string, string) error {
+			deleted.Store(true)
+			return nil
+		},
+	}
+	engine := newTestEngine(client, Options{})
+
+	err := engine.Relocate(context.Background(), gitlab.Variable{Key: "KEY", Value: "v", EnvironmentScope: "*"}, "KEY", "production")
+	require.Error(t, err)
+	assert.False(t, deleted.Load(), "source must not be deleted when create failed")
+}
+
+func TestRelocate_DestinationExists(t *testing.T) {
+	client := &fakeClient{
+		getFn: func(_ context.Context, _, key, envScope string) (*gitlab.Variable, error) {
+			return &gitlab.Variable{Key: key, Value: "x", EnvironmentScope: envScope}, nil
+		},
+	}
+	engine := newTestEngine(client, Options{})
+
+	err := engine.Relocate(context.Background(), gitlab.Variable{Key: "OLD", Value: "v", EnvironmentScope: "*"}, "NEW", "*")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+	assert.Equal(t, int32(1), client.calls.Load(), "only the lookup may run")
+}
+
+func TestRelocate_DryRun(t *testing.T) {
+	client := &fakeClient{}
+	engine := newTestEngine(client, Options{DryRun: true})
+
+	require.NoError(t, engine.Relocate(context.Background(), gitlab.Variable{Key: "OLD", Value: "v", EnvironmentScope: "*"}, "NEW", "*"))
+	assert.Equal(t, int32(1), client.calls.Load(), "dry run must not mutate")
+}
+
+func TestDeleteChanges(t *testing.T) {
+	var deleted []string
+	client := &fakeClient{
+		deleteFn: func(_ context.Context, _, key, envScope string) error {
+			deleted = append(deleted, key+"@"+envScope)
+			return nil
+		},
+	}
+	engine := newTestEngine(client, Options{Workers: 1})
+
+	diff := DeleteChanges([]gitlab.Variable{
+		{Key: "LEGACY_A", Value: "a", EnvironmentScope: "*"},
+		{Key: "LEGACY_B", Value: "b", EnvironmentScope: "review/*"},
+	})
+	report := engine.Apply(context.Background(), diff)
+
+	assert.Equal(t, 2, report.Deleted)
+	assert.ElementsMatch(t, []string{"LEGACY_A@*", "LEGACY_B@review/*"}, deleted)
+}
+
+func TestDiff_WildcardPatternScope_MostSpecificWins(t *testing.T) {
+	engine := newTestEngine(&fakeClient{}, Options{})
+
+	local := []envfile.Variable{{Key: "DB_URL", Value: "new"}, {Key: "API_URL", Value: "api"}}
+	remote := []gitlab.Variable{
+		{Key: "DB_URL", Value: "global", VariableType: "env_var", EnvironmentScope: "*"},
+		{Key: "DB_URL", Value: "review", VariableType: "env_var", EnvironmentScope: "review/*"},
+		{Key: "API_URL", Value: "api", VariableType: "env_var", EnvironmentScope: "review/*"},
+		{Key: "API_URL", Value: "other", VariableType: "env_var", EnvironmentScope: "staging"},
+	}
+
+	diff := engine.Diff(context.Background(), local, remote, "review/feature-x")
+
+	require.Len(t, diff.Changes, 2)
+	assert.Equal(t, ChangeUpdate, diff.Changes[0].Kind)
+	assert.Equal(t, "review", diff.Changes[0].OldValue)
+	assert.Equal(t, "review/*", diff.Changes[0].envScope)
+	assert.Equal(t, ChangeUnchanged, diff.Changes[1].Kind)
+}
@@ -0,0 +1,256 @@
+package sync
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/ohmylock/glenv/pkg/envfile"
+	"github.com/ohmylock/glenv/pkg/gitlab"
+)
+
+// PlanVersion is the plan file format version written by NewPlan.
+const PlanVersion = 2
+
+// hashPrefix marks a value that was replaced by its SHA-256 digest.
+const hashPrefix = "sha256:"
+
+// hmacPrefix marks a value that was replaced by its HMAC-SHA256 keyed with
+// the plan's salt.
+const hmacPrefix = "hmac-sha256:"
+
+// ChangeRecord is the serializable form of a Change. Unlike Change it exposes
+// the internal fields Apply needs to reproduce the API call.
+type ChangeRecord struct {
+	Kind           ChangeKind `json:"kind"`
+	Key            string     `json:"key"`
+	OldValue       string     `json:"old_value,omitempty"`
+	OldValueHash   string     `json:"old_value_hash,omitempty"`
+	NewValue       string     `json:"new_value,omitempty"`
+	NewValueHash   string     `json:"new_value_hash,omitempty"`
+	Classification string     `json:"classification,omitempty"`
+	SkipReason     string     `json:"skip_reason,omitempty"`
+	VarType        string     `json:"variable_type,omitempty"`
+	Masked         bool       `json:"masked"`
+	Protected      bool       `json:"protected"`
+	Raw            bool       `json:"raw"`
+	EnvScope       string     `json:"environment_scope,omitempty"`
+	Secret         bool       `json:"secret,omitempty"` // key matches a secret pattern
+}
+
+// Record returns the serializable form of ch with values in plain text.
+func (ch Change) Record() ChangeRecord {
+	return ChangeRecord{
+		Kind:           ch.Kind,
+		Key:            ch.Key,
+		OldValue:       ch.OldValue,
+		NewValue:       ch.NewValue,
+		Classification: ch.Classification,
+		SkipReason:     ch.SkipReason,
+		VarType:        ch.varType,
+		Masked:         ch.masked,
+		Protected:      ch.protected,
+		Raw:            ch.raw,
+		EnvScope:       ch.envScope,
+		Secret:         ch.secret,
+	}
+}
+
+// change converts r back into a Change. Hashed new values are left empty;
+// a hashed old value is kept so conflict detection can still compare it.
+func (r ChangeRecord) change() Change {
+	return Change{
+		Kind:           r.Kind,
+		Key:            r.Key,
+		OldValue:       r.OldValue,
+		NewValue:       r.NewValue,
+		Classification: r.Classification,
+		SkipReason:     r.SkipReason,
+		varType:        r.VarType,
+		masked:         r.Masked,
+		protected:      r.Protected,
+		raw:            r.Raw,
+		envScope:       r.EnvScope,
+		oldHash:        r.OldValueHash,
+		secret:         r.Secret,
+	}
+}
+
+// isSecret reports whether the record's new value must not be stored in plain
+// text: its key looks like a secret even if the value cannot be masked, it is
+// masked, or it is a file variable, which commonly holds keys and certificates.
+func (r ChangeRecord) isSecret() bool {
+	return r.Secret || r.Masked || r.VarType == "file"
+}
+
+// Plan is a saved DiffResult that can be reviewed and applied later.
+//
+// Remote values are never stored: OldValue is always replaced by its hash.
+// New values of secret variables (secret-named, masked or file type) are hashed
+// too and re-read from the local .env file at apply time. Unchanged and skipped
+// records carry no new value at all. Hashes are HMACs keyed with a random
+// per-plan Salt, so they cannot be looked up in precomputed tables or
+// compared across plans.
+type Plan struct {
+	Version           int            `json:"version"`
+	CreatedAt         time.Time      `json:"created_at"`
+	ProjectID         string         `json:"project_id"`
+	Environment       string         `json:"environment"`
+	File              string         `json:"file,omitempty"`
+	Salt              string         `json:"salt"`
+	RemoteFingerprint string         `json:"remote_fingerprint"`
+	Changes           []ChangeRecord `json:"changes"`
+}
+
+// NewPlan builds a Plan from diff. remote is the variable list the diff was
+// computed against; it is reduced to a fingerprint so Apply can detect drift.
+func NewPlan(diff DiffResult, remote []gitlab.Variable, projectID, environment, file string) *Plan {
+	p := &Plan{
+		Version:     PlanVersion,
+		CreatedAt:   time.Now().UTC(),
+		ProjectID:   projectID,
+		Environment: environment,
+		File:        file,
+		Salt:        rand.Text(),
+		Changes:     make([]ChangeRecord, 0, len(diff.Changes)),
+	}
+	p.RemoteFingerprint = p.Fingerprint(remote)
+	for _, ch := range diff.Changes {
+		r := ch.Record()
+		if r.OldValue != "" {
+			r.OldValueHash = p.HashValue(r.OldValue)
+			r.OldValue = ""
+		}
+		switch {
+		case r.Kind == ChangeUnchanged || r.Kind == ChangeSkipped:
+			// Nothing is applied, so the value is not needed.
+			r.NewValue = ""
+		case r.isSecret() && r.NewValue != "":
+			r.NewValueHash = p.HashValue(r.NewValue)
+			r.NewValue = ""
+		}
+		p.Changes = append(p.Changes, r)
+	}
+	return p
+}
+
+// NeedsLocal reports whether the plan holds hashed values that must be
+// resolved from the local .env file before it can be applied.
+func (p *Plan) NeedsLocal() bool {
+	for _, r := range p.Changes {
+		if r.NewValueHash != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffResult restores the planned changes. Hashed new values are looked up in
+// local by key and must match the hash recorded in the plan; otherwise an
+// error is returned because applying would push a value nobody reviewed.
+func (p *Plan) DiffResult(local []envfile.Variable) (DiffResult, error) {
+	localMap := make(map[string]string, len(local))
+	for _, v := range local {
+		localMap[v.Key] = v.Value
+	}
+
+	var errs []error
+	changes := make([]Change, 0, len(p.Changes))
+	for _, r := range p.Changes {
+		ch := r.change()
+		ch.hashKey = p.Salt
+		if r.NewValueHash != "" {
+			val, ok := localMap[r.Key]
+			switch {
+			case !ok:
+				errs = append(errs, fmt.Errorf("plan: %s: value not found in local file", r.Key))
+				continue
+			case p.HashValue(val) != r.NewValueHash:
+				errs = append(errs, fmt.Errorf("plan: %s: local value differs from the planned value", r.Key))
+				continue
+			}
+			ch.NewValue = val
+		}
+		changes = append(changes, ch)
+	}
+	if len(errs) > 0 {
+		return DiffResult{}, errors.Join(errs...)
+	}
+	return DiffResult{Changes: changes}, nil
+}
+
+// WritePlan encodes p as indented JSON to w.
+func WritePlan(w io.Writer, p *Plan) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(p); err != nil {
+		return fmt.Errorf("plan: encode: %w", err)
+	}
+	return nil
+}
+
+// ReadPlan decodes a plan written by WritePlan.
+func ReadPlan(r io.Reader) (*Plan, error) {
+	var p Plan
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return nil, fmt.Errorf("plan: decode: %w", err)
+	}
+	if p.Version != PlanVersion {
+		return nil, fmt.Errorf("plan: unsupported version %d (want %d)", p.Version, PlanVersion)
+	}
+	return &p, nil
+}
+
+// Fingerprint returns a stable digest of the remote variables visible in the
+// plan's environment, keyed with the plan's salt. Any change to a key, value,
+// type or flag changes the fingerprint.
+func (p *Plan) Fingerprint(remote []gitlab.Variable) string {
+	return Fingerprint(remote, p.Environment, p.Salt)
+}
+
+// HashValue returns the "hmac-sha256:<hex>" digest used to store values in p.
+func (p *Plan) HashValue(value string) string {
+	return keyedHash(p.Salt, value)
+}
+
+// Fingerprint returns a stable digest of the remote variables visible in
+// envScope, keyed with salt.
+func Fingerprint(remote []gitlab.Variable, envScope, salt string) string {
+	vars := gitlab.FilterByScope(remote, envScope)
+	sorted := make([]gitlab.Variable, len(vars))
+	copy(sorted, vars)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Key != sorted[j].Key {
+			return sorted[i].Key < sorted[j].Key
+		}
+		return sorted[i].EnvironmentScope < sorted[j].EnvironmentScope
+	})
+
+	h := hmac.New(sha256.New, []byte(salt))
+	for _, v := range sorted {
+		fmt.Fprintf(h, "%q %q %q %q %t %t %t\n",
+			v.Key, v.EnvironmentScope, v.VariableType, v.Value, v.Masked, v.Protected, v.Raw)
+	}
+	return hmacPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// keyedHash returns the "hmac-sha256:<hex>" digest of value keyed with salt.
+func keyedHash(salt, value string) string {
+	h := hmac.New(sha256.New, []byte(salt))
+	h.Write([]byte(value))
+	return hmacPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// HashValue returns the unkeyed "sha256:<hex>" digest of value, for comparing
+// values without handling them in plain text. It is not stored or shown.
+func HashValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hashPrefix + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,167 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ohmylock/glenv/pkg/envfile"
+	"github.com/ohmylock/glenv/pkg/gitlab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPlan_HashesSecretsAndOldValues(t *testing.T) {
+	engine := newTestEngine(&fakeClient{}, Options{})
+
+	local := []envfile.Variable{
+		{Key: "APP_NAME", Value: "glenv"},
+		{Key: "API_TOKEN", Value: "supersecretvalue123"},
+	}
+	remote := []gitlab.Variable{{Key: "APP_NAME", Value: "old", VariableType: "env_var", EnvironmentScope: "*"}}
+
+	diff := engine.Diff(context.Background(), local, remote, "*")
+	plan := NewPlan(diff, remote, "proj-1", "*", ".env")
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePlan(&buf, plan))
+	assert.NotContains(t, buf.String(), "supersecretvalue123")
+	assert.NotContains(t, buf.String(), `"old"`)
+
+	byKey := map[string]ChangeRecord{}
+	for _, r := range plan.Changes {
+		byKey[r.Key] = r
+	}
+	assert.Equal(t, "glenv", byKey["APP_NAME"].NewValue)
+	assert.Equal(t, plan.HashValue("old"), byKey["APP_NAME"].OldValueHash)
+	assert.NotEqual(t, HashValue("old"), byKey["APP_NAME"].OldValueHash, "hashes must be keyed")
+	assert.Empty(t, byKey["API_TOKEN"].NewValue)
+	assert.Equal(t, plan.HashValue("supersecretvalue123"), byKey["API_TOKEN"].NewValueHash)
+	assert.True(t, byKey["API_TOKEN"].Masked)
+	assert.True(t, plan.NeedsLocal())
+}
+
+func TestNewPlan_HashesUnmaskableSecrets(t *testing.T) {
+	engine := newTestEngine(&fakeClient{}, Options{})
+
+	// Too short and with a space: GitLab cannot mask it, but the key says secret.
+	local := []envfile.Variable{{Key: "DB_PASSWORD", Value: "pa ss!"}}
+	diff := engine.Diff(context.Background(), local, nil, "*")
+	plan := NewPlan(diff, nil, "proj-1", "*", ".env")
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePlan(&buf, plan))
+	assert.NotContains(t, buf.String(), "pa ss!")
+
+	r := plan.Changes[0]
+	assert.False(t, r.Masked)
+	assert.True(t, r.Secret)
+	assert.Empty(t, r.NewValue)
+	assert.Equal(t, plan.HashValue("pa ss!"), r.NewValueHash)
+
+	restored, err := plan.DiffResult(local)
+	require.NoError(t, err)
+	assert.Equal(t, "pa ss!", restored.Changes[0].NewValue)
+}
+
+func TestNewPlan_SaltDiffersPerPlan(t *testing.T) {
+	engine := newTestEngine(&fakeClient{}, Options{})
+	diff := engine.Diff(context.Background(), []envfile.Variable{{Key: "API_TOKEN", Value: "supersecretvalue123"}}, nil, "*")
+
+	a := NewPlan(diff, nil, "proj-1", "*", ".env")
+	b := NewPlan(diff, nil, "proj-1", "*", ".env")
+	assert.NotEmpty(t, a.Salt)
+	assert.NotEqual(t, a.Salt, b.Salt)
+	assert.NotEqual(t, a.Changes[0].NewValueHash, b.Changes[0].NewValueHash)
+	assert.NotEqual(t, a.RemoteFingerprint, b.RemoteFingerprint)
+}
+
+func TestNewPlan_OmitsUnchangedValues(t *testing.T) {
+	engine := newTestEngine(&fakeClient{}, Options{})
+
+	local := []envfile.Variable{
+		{Key: "API_TOKEN", Value: "supersecretvalue123"},
+		{Key: "APP_NAME", Value: "glenv"},
+	}
+	remote := []gitlab.Variable{
+		{Key: "API_TOKEN", Value: "supersecretvalue123", VariableType: "env_var", EnvironmentScope: "*", Masked: true},
+		{Key: "APP_NAME", Value: "glenv", VariableType: "env_var", EnvironmentScope: "*"},
+	}
+
+	diff := engine.Diff(context.Background(), local, remote, "*")
+	plan := NewPlan(diff, remote, "proj-1", "*", ".env")
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePlan(&buf, plan))
+	assert.NotContains(t, buf.String(), "supersecretvalue123")
+	for _, r := range plan.Changes {
+		assert.Equal(t, ChangeUnchanged, r.Kind, r.Key)
+		assert.Empty(t, r.NewValue, r.Key)
+		assert.Empty(t, r.NewValueHash, r.Key)
+	}
+	assert.False(t, plan.NeedsLocal())
+}
+
+func TestPlan_RoundTrip(t *testing.T) {
+	engine := newTestEngine(&fakeClient{}, Options{})
+	local := []envfile.Variable{{Key: "API_TOKEN", Value: "supersecretvalue123"}}
+	diff := engine.Diff(context.Background(), local, nil, "production")
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePlan(&buf, NewPlan(diff, nil, "proj-1", "production", ".env")))
+
+	plan, err := ReadPlan(&buf)
+	require.NoError(t, err)
+	restored, err := plan.DiffResult(local)
+	require.NoError(t, err)
+
+	require.Len(t, restored.Changes, 1)
+	ch := restored.Changes[0]
+	assert.Equal(t, ChangeCreate, ch.Kind)
+	assert.Equal(t, "supersecretvalue123", ch.NewValue)
+	assert.Equal(t, "production", ch.envScope)
+	assert.True(t, ch.masked)
+	assert.True(t, ch.protected)
+}
+
+func TestPlan_DiffResult_LocalMismatch(t *testing.T) {
+	plan := &Plan{Version: PlanVersion, Salt: "salt"}
+	plan.Changes = []ChangeRecord{
+		{Kind: ChangeCreate, Key: "API_TOKEN", NewValueHash: plan.HashValue("reviewed-value"), Masked: true},
+	}
+
+	_, err := plan.DiffResult([]envfile.Variable{{Key: "API_TOKEN", Value: "edited-value"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "differs")
+
+	_, err = plan.DiffResult(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestReadPlan_UnsupportedVersion(t *testing.T) {
+	_, err := ReadPlan(strings.NewReader(`{"version": 99}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported version")
+}
+
+func TestFingerprint(t *testing.T) {
+	remote := []gitlab.Variable{
+		{Key: "B", Value: "2", EnvironmentScope: "*"},
+		{Key: "A", Value: "1", EnvironmentScope: "*"},
+		{Key: "C", Value: "3", EnvironmentScope: "staging"},
+	}
+	reordered := []gitlab.Variable{remote[2], remote[1], remote[0]}
+
+	fp := Fingerprint(remote, "*", "salt")
+	assert.Equal(t, fp, Fingerprint(reordered, "*", "salt"), "order must not matter")
+
+	// Variables in other scopes do not affect the fingerprint.
+	otherScope := append(remote[:2:2], gitlab.Variable{Key: "C", Value: "changed", EnvironmentScope: "staging"})
+	assert.Equal(t, fp, Fingerprint(otherScope, "*", "salt"))
+
+	changed := []gitlab.Variable{remote[0], {Key: "A", Value: "1", EnvironmentScope: "*", Protected: true}}
+	assert.NotEqual(t, fp, Fingerprint(changed, "*", "salt"))
+	assert.NotEqual(t, fp, Fingerprint(remote, "*", "other"), "fingerprint must be keyed")
+}